@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	ucfgflag "github.com/elastic/go-ucfg/flag"
+	"github.com/leehinman/spigot/pkg/bench"
+)
+
+// benchmark runs a generator, and optionally an output, configured
+// from flags for a fixed time and reports the throughput.
+func benchmark(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	genType := fs.String("g", "", "generator type, see list")
+	outType := fs.String("o", "", "output type, default discard the records")
+	duration := fs.Duration("d", 10*time.Second, "how long to run for")
+	seed := fs.Int64("s", 1, "seed for the random number generator")
+	genSettings := ucfgflag.NewFlagKeyValue(ucfg.New(), false, ucfg.PathSep("."))
+	fs.Var(genSettings, "set", "generator option as key=value, may be repeated")
+	outSettings := ucfgflag.NewFlagKeyValue(ucfg.New(), false, ucfg.PathSep("."))
+	fs.Var(outSettings, "oset", "output option as key=value, may be repeated")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s bench -g type [-d duration] [--set key=value]... [-o type [--oset key=value]...]\n\nFlags:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *genType == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	c := bench.Config{Duration: *duration, Seed: *seed}
+	c.Generator = genSettings.Config()
+	if err := c.Generator.SetString("type", -1, *genType, ucfg.PathSep(".")); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if *outType != "" {
+		c.Output = outSettings.Config()
+		if err := c.Output.SetString("type", -1, *outType, ucfg.PathSep(".")); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	// SIGINT ends the benchmark early and still reports the results.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	r, err := bench.Run(ctx, c)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	out := "discard"
+	if *outType != "" {
+		out = *outType
+	}
+	allocs, allocBytes := r.AllocsPerRecord()
+	fmt.Printf("%s -> %s: %d records, %d bytes in %v\n", *genType, out, r.Records, r.Bytes, r.Elapsed.Round(time.Millisecond))
+	fmt.Printf("  %.0f records/s, %.2f MB/s\n", r.RecordsPerSecond(), r.MBPerSecond())
+	fmt.Printf("  %.1f allocs/record, %.0f B/record\n", allocs, allocBytes)
+	fmt.Printf("  Next()  p50 %v, p99 %v\n", r.Next.Quantile(0.5), r.Next.Quantile(0.99))
+	fmt.Printf("  Write() p50 %v, p99 %v\n", r.Write.Quantile(0.5), r.Write.Quantile(0.99))
+	if *outType != "" {
+		fmt.Printf("  Close() %v\n", r.Close.Round(time.Microsecond))
+	}
+	return 0
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/leehinman/spigot/pkg/describe"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// list prints the registered generators and outputs.
+func list(args []string) int {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Generators:")
+	for _, name := range generator.Names() {
+		info, _ := generator.GetInfo(name)
+		fmt.Fprintf(w, "  %s\t%s\n", name, info.Description)
+	}
+	fmt.Fprintln(w, "\nOutputs:")
+	for _, name := range output.Names() {
+		info, _ := output.GetInfo(name)
+		fmt.Fprintf(w, "  %s\t%s\n", name, info.Description)
+	}
+	_ = w.Flush()
+	return 0
+}
+
+// describeType prints the options of the generators and outputs named
+// in args, and a sample record for generators.
+func describeType(args []string) int {
+	fs := flag.NewFlagSet("describe", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s describe type...\n", os.Args[0])
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+
+	failed := false
+	for i, name := range fs.Args() {
+		if i > 0 {
+			fmt.Println()
+		}
+		found := false
+		// A name may be both a generator and an output, e.g. winlog.
+		if info, err := generator.GetInfo(name); err == nil {
+			found = true
+			fmt.Printf("%s (generator)\n", name)
+			printInfo(os.Stdout, info.Description, info.Config)
+			sample, err := sampleRecord(name, info.Example)
+			fmt.Println("\nExample:")
+			if err != nil {
+				fmt.Printf("  no sample: %v\n", err)
+			} else {
+				fmt.Printf("  %s\n", strings.ReplaceAll(string(sample), "\n", "\n  "))
+			}
+		}
+		if info, err := output.GetInfo(name); err == nil {
+			if found {
+				fmt.Println()
+			}
+			found = true
+			fmt.Printf("%s (output)\n", name)
+			printInfo(os.Stdout, info.Description, info.Config)
+		}
+		if !found {
+			fmt.Fprintf(os.Stderr, "%s is not a registered generator or output\n", name)
+			failed = true
+		}
+	}
+	if failed {
+		return 1
+	}
+	return 0
+}
+
+func printInfo(out io.Writer, description string, cfg interface{}) {
+	if description != "" {
+		fmt.Fprintf(out, "  %s\n", description)
+	}
+	opts := describe.Options(cfg)
+	if len(opts) == 0 {
+		return
+	}
+	fmt.Fprintln(out, "\nOptions:")
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, o := range opts {
+		var notes []string
+		if o.Required {
+			notes = append(notes, "required")
+		}
+		if o.Default != "" {
+			notes = append(notes, "default "+o.Default)
+		}
+		if len(notes) == 0 {
+			fmt.Fprintf(w, "  %s\t%s\n", o.Name, o.Type)
+			continue
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", o.Name, o.Type, strings.Join(notes, ", "))
+	}
+	_ = w.Flush()
+}
+
+// sampleRecord generates one record from the generator name, with
+// example as its config if set.
+func sampleRecord(name string, example map[string]interface{}) ([]byte, error) {
+	if example == nil {
+		example = map[string]interface{}{"type": name}
+	}
+	cfg, err := ucfg.NewFrom(example, ucfg.PathSep("."))
+	if err != nil {
+		return nil, err
+	}
+	g, err := generator.New(cfg, rand.New(rand.NewSource(1)), clock.Real)
+	if err != nil {
+		return nil, err
+	}
+	return g.Next()
+}
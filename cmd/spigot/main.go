@@ -1,74 +1,186 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"math/rand"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/elastic/go-ucfg"
 	"github.com/elastic/go-ucfg/yaml"
+	"github.com/leehinman/spigot/pkg/control"
+	"github.com/leehinman/spigot/pkg/metrics"
 	"github.com/leehinman/spigot/pkg/runner"
 )
 
 type Config struct {
-	Runners []*ucfg.Config `config:"runners" validate:"required"`
+	Runners []*ucfg.Config  `config:"runners" validate:"required"`
+	Metrics *metrics.Config `config:"metrics"`
+	Control *control.Config `config:"control"`
 }
 
-type Result struct {
-	Done  bool
-	Error error
+// commands are the subcommands, which take the arguments after their
+// name and return the exit code.
+var commands = map[string]func(args []string) int{
+	"validate": validate,
+	"list":     list,
+	"describe": describeType,
+	"sample":   sample,
+	"bench":    benchmark,
 }
 
-func execute_runner(cfg *ucfg.Config, results chan Result) {
-	r, err := runner.New(cfg)
-	if err != nil {
-		results <- Result{Error: err}
-		return
-	}
-	err = r.Execute()
-	if err != nil {
-		results <- Result{Error: err}
-		return
+func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			os.Exit(cmd(os.Args[2:]))
+		}
 	}
-	results <- Result{Done: true}
-	return
-}
 
-func main() {
-	var cfgFile string
-	var randomize bool
+	var cfgFile, stateFile string
+	var randomize, resume bool
 
 	flag.StringVar(&cfgFile, "c", "./spigot.yml", "path to configuration file")
 	flag.BoolVar(&randomize, "r", false, "seed random number generator with current time")
+	flag.StringVar(&stateFile, "state", "", "path to state file to save the progress of runners to")
+	flag.BoolVar(&resume, "resume", false, "continue runners from the checkpoints in the state file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %[1]s [flags]\n       %[1]s -state file [-resume] [flags]\n       %[1]s validate [-c file]\n       %[1]s list\n       %[1]s describe type...\n       %[1]s sample -g type [-n count] [--set key=value]...\n       %[1]s bench -g type [-d duration] [-o type]\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
-	c := Config{}
-	cfg, err := yaml.NewConfigWithFile(cfgFile, ucfg.PathSep("."))
-	if err != nil {
-		panic(err)
+	if resume && stateFile == "" {
+		fmt.Fprintln(os.Stderr, "-resume requires -state")
+		os.Exit(2)
 	}
-	err = cfg.Unpack(&c)
+
+	c, err := loadConfig(cfgFile)
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
 	if randomize {
 		rand.Seed(time.Now().UnixNano())
 	}
 
-	resultCh := make(chan Result)
+	// A signal stops all runners, which then close their outputs.  A
+	// failing runner does not stop the others.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	m := runner.NewManager(ctx)
+	if stateFile != "" {
+		checkpoints, err := runner.NewCheckpoints(stateFile, resume)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		m.UseCheckpoints(checkpoints)
+	}
+	failed := false
+	if err := m.Reload(c.Runners); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		failed = true
+	}
+
+	// SIGHUP reloads the runners from the config file.  Changes to
+	// metrics and control are ignored.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-hup:
+				reload(m, cfgFile)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	if c.Metrics != nil {
+		go func() {
+			if err := metrics.Serve(ctx, *c.Metrics, m.Runners); err != nil {
+				fmt.Fprintf(os.Stderr, "metrics: %v\n", err)
+			}
+		}()
+	}
 
-	for _, rCfg := range c.Runners {
-		rCfg := rCfg
+	// With a control API runners may be started at any time, so
+	// spigot runs until it is stopped by a signal.
+	if c.Control != nil {
 		go func() {
-			execute_runner(rCfg, resultCh)
+			if err := control.Serve(ctx, *c.Control, m); err != nil {
+				fmt.Fprintf(os.Stderr, "control: %v\n", err)
+			}
 		}()
+		<-ctx.Done()
+	}
+
+	results := m.Wait()
+	for _, r := range results {
+		if r.Error != nil {
+			failed = true
+		}
+	}
+
+	printSummary(results)
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func loadConfig(path string) (Config, error) {
+	c := Config{}
+	cfg, err := yaml.NewConfigWithFile(path, ucfg.PathSep("."))
+	if err != nil {
+		return c, fmt.Errorf("loading %s: %w", path, err)
 	}
+	if err := cfg.Unpack(&c); err != nil {
+		return c, fmt.Errorf("loading %s: %w", path, err)
+	}
+	return c, nil
+}
 
-	for i := 0; i < len(c.Runners); i++ {
-		r := <-resultCh
-		if !r.Done {
-			panic(r.Error)
+// reload re-reads the config file and reloads the runners.  If the
+// file cannot be read the runners are left as they are.
+func reload(m *runner.Manager, path string) {
+	c, err := loadConfig(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "reload: %v\n", err)
+		return
+	}
+	if err := m.Reload(c.Runners); err != nil {
+		fmt.Fprintf(os.Stderr, "reload: %v\n", err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "reloaded %s\n", path)
+}
+
+// printSummary writes one line per runner to stderr with what it
+// wrote and how it ended.
+func printSummary(results []runner.Result) {
+	for _, r := range results {
+		s := r.Runner.Stats()
+		elapsed := r.Runner.Elapsed()
+		fmt.Fprintf(os.Stderr, "runner %s (%s): %d records, %d bytes in %v",
+			r.Runner.Name(), r.Runner, s.Records.Load(), s.Bytes.Load(), elapsed.Round(time.Millisecond))
+		if secs := elapsed.Seconds(); secs > 0 {
+			fmt.Fprintf(os.Stderr, " (%.0f records/s, %.2f MB/s)",
+				float64(s.Records.Load())/secs, float64(s.Bytes.Load())/secs/1e6)
+		}
+		if e := s.WriteErrors.Load(); e > 0 {
+			fmt.Fprintf(os.Stderr, ", %d write errors, %d retries, %d reconnects, %d dropped",
+				e, s.Retries.Load(), s.Reconnects.Load(), s.Dropped.Load())
+		}
+		if r.Error != nil {
+			fmt.Fprintf(os.Stderr, ": %v", r.Error)
 		}
+		fmt.Fprintln(os.Stderr)
 	}
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+
+	"github.com/elastic/go-ucfg"
+	ucfgflag "github.com/elastic/go-ucfg/flag"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/leehinman/spigot/pkg/generator"
+)
+
+// sample writes records of a generator, configured from flags, to
+// stdout, one per line.
+func sample(args []string) int {
+	fs := flag.NewFlagSet("sample", flag.ExitOnError)
+	typ := fs.String("g", "", "generator type, see list")
+	count := fs.Int("n", 10, "number of records to write")
+	seed := fs.Int64("s", 0, "seed for the random number generator, default random")
+	settings := ucfgflag.NewFlagKeyValue(ucfg.New(), false, ucfg.PathSep("."))
+	fs.Var(settings, "set", "generator option as key=value, may be repeated")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s sample -g type [-n count] [-s seed] [--set key=value]...\n\nFlags:\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if *typ == "" || fs.NArg() > 0 {
+		fs.Usage()
+		return 2
+	}
+
+	seeded := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "s" {
+			seeded = true
+		}
+	})
+	if !seeded {
+		*seed = rand.Int63()
+	}
+
+	cfg := settings.Config()
+	if err := cfg.SetString("type", -1, *typ, ucfg.PathSep(".")); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	g, err := generator.New(cfg, rand.New(rand.NewSource(*seed)), clock.Real)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	for i := 0; i < *count; i++ {
+		b, err := g.Next()
+		if err != nil {
+			w.Flush()
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		w.Write(b)
+		w.WriteByte('\n')
+	}
+	return 0
+}
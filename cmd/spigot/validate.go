@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/leehinman/spigot/pkg/runner"
+)
+
+// validate checks the config file without opening any output and
+// prints every error found.
+func validate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	cfgFile := fs.String("c", "./spigot.yml", "path to configuration file")
+	_ = fs.Parse(args)
+
+	c, err := loadConfig(*cfgFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	failed := false
+	for _, rCfg := range c.Runners {
+		if err := runner.Validate(rCfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+		}
+	}
+	if failed {
+		return 1
+	}
+	fmt.Printf("%s: %d runners ok\n", *cfgFile, len(c.Runners))
+	return 0
+}
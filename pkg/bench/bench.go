@@ -0,0 +1,203 @@
+// Package bench measures how fast a generator produces records and,
+// optionally, how fast an output writes them, to find out whether
+// spigot or the destination is the bottleneck.
+//
+// Records are generated and written in a single loop for a fixed
+// time.  Without an output records are written to io.Discard.  The
+// output is closed before the clock stops, so outputs that buffer
+// records and send them on Close, like s3, are measured up to the
+// destination.  The latencies of Next() and Write() are recorded in
+// log-linear buckets accurate to about 6%, and allocations are read
+// from the Go runtime, so they include those of the output.
+package bench
+
+import (
+	"context"
+	"io"
+	"math/bits"
+	"math/rand"
+	"runtime"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Config is what to benchmark.  Output is optional.
+type Config struct {
+	Generator *ucfg.Config
+	Output    *ucfg.Config
+	Duration  time.Duration
+	Seed      int64
+}
+
+// Result is the outcome of a benchmark.
+type Result struct {
+	Records uint64
+	Bytes   uint64
+	Elapsed time.Duration
+	// Close is how long closing the output took, it is included in
+	// Elapsed.
+	Close      time.Duration
+	Allocs     uint64
+	AllocBytes uint64
+	Next       Latency
+	Write      Latency
+}
+
+// RecordsPerSecond returns the records written per second.
+func (r *Result) RecordsPerSecond() float64 {
+	return float64(r.Records) / r.Elapsed.Seconds()
+}
+
+// MBPerSecond returns the megabytes of records written per second.
+func (r *Result) MBPerSecond() float64 {
+	return float64(r.Bytes) / r.Elapsed.Seconds() / 1e6
+}
+
+// AllocsPerRecord returns the number of heap allocations and the
+// bytes allocated per record.
+func (r *Result) AllocsPerRecord() (float64, float64) {
+	if r.Records == 0 {
+		return 0, 0
+	}
+	return float64(r.Allocs) / float64(r.Records), float64(r.AllocBytes) / float64(r.Records)
+}
+
+// Run generates and writes records until c.Duration has passed or ctx
+// is done.
+func Run(ctx context.Context, c Config) (*Result, error) {
+	g, err := generator.New(c.Generator, rand.New(rand.NewSource(c.Seed)), clock.Real)
+	if err != nil {
+		return nil, err
+	}
+	var w io.Writer = io.Discard
+	var o output.Output
+	if c.Output != nil {
+		o, err = output.New(c.Output)
+		if err != nil {
+			return nil, err
+		}
+		w = o
+	}
+
+	r := &Result{}
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	deadline := start.Add(c.Duration)
+	for {
+		t0 := time.Now()
+		if !t0.Before(deadline) {
+			break
+		}
+		// Checking ctx on every record would show up in the results.
+		if r.Records%1024 == 0 && ctx.Err() != nil {
+			break
+		}
+		b, err := g.Next()
+		t1 := time.Now()
+		if err != nil {
+			closeOutput(o)
+			return r, err
+		}
+		if _, err := w.Write(b); err != nil {
+			closeOutput(o)
+			return r, err
+		}
+		t2 := time.Now()
+		r.Next.Observe(t1.Sub(t0))
+		r.Write.Observe(t2.Sub(t1))
+		r.Records++
+		r.Bytes += uint64(len(b))
+	}
+	if o != nil {
+		t0 := time.Now()
+		err = o.Close()
+		r.Close = time.Since(t0)
+	}
+	r.Elapsed = time.Since(start)
+	runtime.ReadMemStats(&after)
+	r.Allocs = after.Mallocs - before.Mallocs
+	r.AllocBytes = after.TotalAlloc - before.TotalAlloc
+	return r, err
+}
+
+// closeOutput closes o, if there is one, after the benchmark failed.
+func closeOutput(o output.Output) {
+	if o != nil {
+		_ = o.Close()
+	}
+}
+
+// subBuckets is the number of buckets each power of two is split
+// into.
+const subBuckets = 8
+
+// Latency is a histogram of durations with buckets that are exact
+// below 8ns and about 12% wide above that.  It does not allocate.
+type Latency struct {
+	counts [64 * subBuckets]uint64
+	count  uint64
+}
+
+// Observe records d.
+func (l *Latency) Observe(d time.Duration) {
+	l.counts[bucket(d)]++
+	l.count++
+}
+
+// Count returns the number of durations recorded.
+func (l *Latency) Count() uint64 {
+	return l.count
+}
+
+// Quantile returns the duration that q of the recorded durations are
+// less than or equal to, for example 0.99 for the 99th percentile.
+// It returns the middle of the bucket the duration falls in.
+func (l *Latency) Quantile(q float64) time.Duration {
+	if l.count == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(l.count))
+	if rank >= l.count {
+		rank = l.count - 1
+	}
+	var seen uint64
+	for i, n := range l.counts {
+		seen += n
+		if seen > rank {
+			low, high := bucketBounds(i)
+			return time.Duration(low + (high-low)/2)
+		}
+	}
+	return 0
+}
+
+func bucket(d time.Duration) int {
+	if d < subBuckets {
+		if d < 0 {
+			return 0
+		}
+		return int(d)
+	}
+	v := uint64(d)
+	e := bits.Len64(v) - 1
+	m := int(v>>(e-3)) & (subBuckets - 1)
+	return subBuckets*(e-2) + m
+}
+
+// bucketBounds returns the smallest and largest durations, in
+// nanoseconds, of bucket i.
+func bucketBounds(i int) (uint64, uint64) {
+	if i < subBuckets {
+		return uint64(i), uint64(i)
+	}
+	e := i/subBuckets + 2
+	m := uint64(i % subBuckets)
+	low := (subBuckets + m) << (e - 3)
+	return low, low + 1<<(e-3) - 1
+}
@@ -0,0 +1,107 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	_ "github.com/leehinman/spigot/pkg/include"
+	"github.com/leehinman/spigot/pkg/output"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatency(t *testing.T) {
+	tests := map[string]struct {
+		durations []time.Duration
+		q         float64
+		want      time.Duration
+	}{
+		"Empty": {
+			q:    0.5,
+			want: 0,
+		},
+		"Exact": {
+			durations: []time.Duration{1, 2, 3, 4, 5},
+			q:         0.5,
+			want:      3,
+		},
+		"Max": {
+			durations: []time.Duration{1, 2, 3, 4, 5},
+			q:         1,
+			want:      5,
+		},
+		"Large": {
+			durations: []time.Duration{time.Microsecond, time.Millisecond, time.Second},
+			q:         0.99,
+			want:      time.Second,
+		},
+	}
+	for name, tc := range tests {
+		var l Latency
+		for _, d := range tc.durations {
+			l.Observe(d)
+		}
+		assert.Equal(t, uint64(len(tc.durations)), l.Count(), name)
+		got := l.Quantile(tc.q)
+		assert.InEpsilon(t, float64(tc.want)+1, float64(got)+1, 0.07, name)
+	}
+}
+
+func TestBuckets(t *testing.T) {
+	for _, d := range []time.Duration{0, 7, 8, 15, 16, 1000, 123456789, time.Hour} {
+		low, high := bucketBounds(bucket(d))
+		assert.LessOrEqual(t, low, uint64(d), d)
+		assert.GreaterOrEqual(t, high, uint64(d), d)
+	}
+}
+
+func TestRun(t *testing.T) {
+	c := Config{
+		Generator: ucfg.MustNewFrom(map[string]interface{}{"type": "aws:vpcflow"}),
+		Duration:  20 * time.Millisecond,
+	}
+	r, err := Run(context.Background(), c)
+	assert.Nil(t, err)
+	assert.NotZero(t, r.Records)
+	assert.NotZero(t, r.Bytes)
+	assert.Equal(t, r.Records, r.Next.Count())
+	assert.Equal(t, r.Records, r.Write.Count())
+	assert.GreaterOrEqual(t, r.Elapsed, c.Duration)
+	assert.Greater(t, r.RecordsPerSecond(), 0.0)
+
+	c.Generator = ucfg.MustNewFrom(map[string]interface{}{"type": "bob"})
+	_, err = Run(context.Background(), c)
+	assert.NotNil(t, err)
+}
+
+// slowOutput takes closeTime to close and fails.
+type slowOutput struct {
+	closeTime time.Duration
+}
+
+func (o *slowOutput) Write(p []byte) (int, error) { return len(p), nil }
+func (o *slowOutput) NewInterval() error          { return nil }
+func (o *slowOutput) Close() error {
+	time.Sleep(o.closeTime)
+	return errors.New("upload failed")
+}
+
+func TestRunClose(t *testing.T) {
+	err := output.Register("bench:slow", func(*ucfg.Config) (output.Output, error) {
+		return &slowOutput{closeTime: 30 * time.Millisecond}, nil
+	})
+	assert.Nil(t, err)
+	c := Config{
+		Generator: ucfg.MustNewFrom(map[string]interface{}{"type": "aws:vpcflow"}),
+		Output:    ucfg.MustNewFrom(map[string]interface{}{"type": "bench:slow"}),
+		Duration:  10 * time.Millisecond,
+	}
+	r, err := Run(context.Background(), c)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "upload failed", err.Error())
+	}
+	assert.GreaterOrEqual(t, r.Close, 30*time.Millisecond)
+	assert.GreaterOrEqual(t, r.Elapsed, c.Duration+r.Close)
+}
@@ -0,0 +1,87 @@
+// Package clock provides the time that generators put in records, so
+// that records can be dated in the past and generated faster than
+// real time, for example to backfill a week of logs in minutes.
+package clock
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the interface that wraps the Now method.
+//
+// Now returns the time to use for the record being generated.  It may
+// be called concurrently.
+type Clock interface {
+	Now() time.Time
+}
+
+// Real is the wall clock.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// Fixed returns a clock that is always at t.
+func Fixed(t time.Time) Clock {
+	return fixedClock{t: t}
+}
+
+type fixedClock struct {
+	t time.Time
+}
+
+func (c fixedClock) Now() time.Time {
+	return c.t
+}
+
+// Virtual is a clock that starts at a given time and then either runs
+// "speed" times faster than real time, or advances by "step" every
+// time Tick is called, regardless of real time.
+type Virtual struct {
+	start time.Time
+	speed float64
+	step  time.Duration
+
+	// began is the real time the clock was started, in Unix
+	// nanoseconds, and ticks the number of times Tick was called.
+	began atomic.Int64
+	ticks atomic.Int64
+}
+
+// NewVirtual returns a clock at start.  If step is greater than zero
+// the clock advances by step on every Tick, otherwise it advances
+// speed times faster than real time from when it is created or last
+// started.
+func NewVirtual(start time.Time, speed float64, step time.Duration) *Virtual {
+	v := &Virtual{start: start, speed: speed, step: step}
+	v.began.Store(time.Now().UnixNano())
+	return v
+}
+
+// Start sets the clock back to its start time.  A clock that runs
+// with real time starts running from now.
+func (v *Virtual) Start() {
+	v.began.Store(time.Now().UnixNano())
+	v.ticks.Store(0)
+}
+
+// Tick advances a clock with a step by one step.  It is called for
+// every record.
+func (v *Virtual) Tick() {
+	if v.step > 0 {
+		v.ticks.Add(1)
+	}
+}
+
+// Now returns the current time of the clock.
+func (v *Virtual) Now() time.Time {
+	if v.step > 0 {
+		return v.start.Add(time.Duration(v.ticks.Load()) * v.step)
+	}
+	elapsed := time.Duration(time.Now().UnixNano() - v.began.Load())
+	return v.start.Add(time.Duration(float64(elapsed) * v.speed))
+}
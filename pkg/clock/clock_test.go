@@ -0,0 +1,27 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVirtual(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	v := NewVirtual(start, 0, time.Second)
+	assert.Equal(t, start, v.Now())
+	v.Tick()
+	v.Tick()
+	assert.Equal(t, start.Add(2*time.Second), v.Now())
+	v.Start()
+	assert.Equal(t, start, v.Now())
+
+	v = NewVirtual(start, 1000, 0)
+	time.Sleep(10 * time.Millisecond)
+	v.Tick()
+	assert.True(t, v.Now().Sub(start) >= 10*time.Second)
+
+	assert.Equal(t, start, Fixed(start).Now())
+}
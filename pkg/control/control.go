@@ -0,0 +1,216 @@
+// Package control provides an HTTP API to list and control runners
+// while they execute.
+//
+//	Configuration.
+//
+//	One of "address", the host:port to listen on, or "socket", the
+//	path of a unix socket to listen on, is required.
+//
+//	Example:
+//
+//	  control:
+//	    socket: "/var/run/spigot.sock"
+//	  runners:
+//	    - ...
+//
+//	Endpoints:
+//
+//	  GET    /runners               lists the runners.
+//	  POST   /runners               starts a runner from the runner
+//	                                config, YAML or JSON, in the body.
+//	  GET    /runners/{name}        describes a runner.
+//	  DELETE /runners/{name}        stops a runner.
+//	  POST   /runners/{name}/pause  pauses a runner.
+//	  POST   /runners/{name}/resume resumes a paused runner.
+//	  POST   /runners/{name}/tune   changes "records", "interval" and
+//	                                "rate" of a runner from the YAML
+//	                                or JSON body.
+//
+//	For example:
+//
+//	  curl --unix-socket /var/run/spigot.sock -d '{"rate": {"events": 500}}' http://spigot/runners/0/tune
+//
+//	Responses are JSON.  Errors are returned as {"error": "..."}.
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/elastic/go-ucfg/yaml"
+	"github.com/leehinman/spigot/pkg/runner"
+)
+
+// Config is the configuration of the control API listener.
+type Config struct {
+	Address string `config:"address"`
+	Socket  string `config:"socket"`
+}
+
+func (c *Config) Validate() error {
+	if (c.Address == "") == (c.Socket == "") {
+		return fmt.Errorf("exactly one of 'address' or 'socket' must be set")
+	}
+	return nil
+}
+
+// Runner is the JSON view of a runner.
+type Runner struct {
+	Name      string   `json:"name"`
+	Generator string   `json:"generator"`
+	Outputs   []string `json:"outputs"`
+	State     string   `json:"state"`
+	Records   int      `json:"records"`
+	Interval  string   `json:"interval,omitempty"`
+	Events    float64  `json:"events,omitempty"`
+	Bytes     float64  `json:"bytes,omitempty"`
+	Elapsed   string   `json:"elapsed"`
+	Written   uint64   `json:"written"`
+	Error     string   `json:"error,omitempty"`
+}
+
+func newRunner(info runner.Info) Runner {
+	r := Runner{
+		Name:      info.Name,
+		Generator: info.Generator,
+		Outputs:   info.Outputs,
+		State:     info.State,
+		Records:   info.Records,
+		Events:    info.Events,
+		Bytes:     info.Bytes,
+		Elapsed:   info.Elapsed.Round(time.Millisecond).String(),
+		Written:   info.Written,
+		Error:     info.Error,
+	}
+	if info.Interval > 0 {
+		r.Interval = info.Interval.String()
+	}
+	return r
+}
+
+// Handler returns a handler for the control API of the runners of m.
+func Handler(m *runner.Manager) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /runners", func(w http.ResponseWriter, _ *http.Request) {
+		infos := m.Info()
+		runners := make([]Runner, len(infos))
+		for i, info := range infos {
+			runners[i] = newRunner(info)
+		}
+		writeJSON(w, http.StatusOK, runners)
+	})
+	mux.HandleFunc("POST /runners", func(w http.ResponseWriter, req *http.Request) {
+		cfg, err := readConfig(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		r, err := m.Start(cfg)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, newRunner(r.Info()))
+	})
+	mux.HandleFunc("GET /runners/{name}", withRunner(m, func(w http.ResponseWriter, _ *http.Request, r *runner.Runner) {
+		writeJSON(w, http.StatusOK, newRunner(r.Info()))
+	}))
+	mux.HandleFunc("DELETE /runners/{name}", withRunner(m, func(w http.ResponseWriter, _ *http.Request, r *runner.Runner) {
+		if err := m.Stop(r.Name()); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newRunner(r.Info()))
+	}))
+	mux.HandleFunc("POST /runners/{name}/pause", withRunner(m, func(w http.ResponseWriter, _ *http.Request, r *runner.Runner) {
+		respond(w, r, r.Pause())
+	}))
+	mux.HandleFunc("POST /runners/{name}/resume", withRunner(m, func(w http.ResponseWriter, _ *http.Request, r *runner.Runner) {
+		respond(w, r, r.Resume())
+	}))
+	mux.HandleFunc("POST /runners/{name}/tune", withRunner(m, func(w http.ResponseWriter, req *http.Request, r *runner.Runner) {
+		cfg, err := readConfig(req)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		respond(w, r, r.Tune(cfg))
+	}))
+	return mux
+}
+
+// withRunner looks up the runner named in the path and returns 404 if
+// there is none.
+func withRunner(m *runner.Manager, h func(http.ResponseWriter, *http.Request, *runner.Runner)) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		r, err := m.Get(req.PathValue("name"))
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		h(w, req, r)
+	}
+}
+
+func respond(w http.ResponseWriter, r *runner.Runner, err error) {
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newRunner(r.Info()))
+}
+
+// readConfig parses the YAML or JSON body of req.
+func readConfig(req *http.Request) (*ucfg.Config, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.NewConfig(body, ucfg.PathSep("."))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// Serve listens on the configured address or socket and serves the
+// control API of the runners of m until ctx is done.
+func Serve(ctx context.Context, c Config, m *runner.Manager) error {
+	network, address := "tcp", c.Address
+	if c.Socket != "" {
+		network, address = "unix", c.Socket
+		// Remove a socket left behind by a previous run.
+		if err := os.Remove(c.Socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	l, err := net.Listen(network, address)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: Handler(m), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	if err := srv.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
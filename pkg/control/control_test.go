@@ -0,0 +1,148 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/runner"
+	"github.com/stretchr/testify/assert"
+)
+
+func runnerConfig(t *testing.T) string {
+	return fmt.Sprintf(`{"generator": {"type": "clf"}, "output": {"type": "file", "filename": %q}, "records": 1, "interval": "1ms"}`,
+		filepath.Join(t.TempDir(), "clf.log"))
+}
+
+func do(t *testing.T, client *http.Client, method, url, body string) (int, string) {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.NoError(t, err)
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return resp.StatusCode, string(b)
+}
+
+func TestHandler(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := runner.NewManager(ctx)
+	cfg, err := readConfig(httptest.NewRequest("POST", "/", strings.NewReader(runnerConfig(t))))
+	assert.NoError(t, err)
+	_, err = m.Start(cfg)
+	assert.NoError(t, err)
+
+	srv := httptest.NewServer(Handler(m))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		body     string
+		status   int
+		contains string
+	}{
+		{"List", "GET", "/runners", "", http.StatusOK, `"name":"0","generator":"clf","outputs":["file"],"state":"running"`},
+		{"Get", "GET", "/runners/0", "", http.StatusOK, `"records":1,"interval":"1ms"`},
+		{"Not Found", "GET", "/runners/9", "", http.StatusNotFound, `{"error":"runner 9 not found"}`},
+		{"Pause", "POST", "/runners/0/pause", "", http.StatusOK, `"state":"paused"`},
+		{"Resume", "POST", "/runners/0/resume", "", http.StatusOK, `"state":"running"`},
+		{"Tune", "POST", "/runners/0/tune", "records: 2\nrate:\n  events: 100\n", http.StatusOK, `"name":"0"`},
+		{"Tune Invalid", "POST", "/runners/0/tune", `{"records": 0}`, http.StatusBadRequest, `{"error":"'0' is not a valid value for 'records' expected >= 1 accessing config"}`},
+		{"Start", "POST", "/runners", runnerConfig(t), http.StatusCreated, `"name":"1"`},
+		{"Start Invalid", "POST", "/runners", `{"generator": {"type": "clf"}}`, http.StatusBadRequest, `{"error":"one of 'output' or 'outputs' is required accessing config"}`},
+		{"Stop", "DELETE", "/runners/1", "", http.StatusOK, `"name":"1"`},
+	}
+	for _, tc := range tests {
+		status, body := do(t, srv.Client(), tc.method, srv.URL+tc.path, tc.body)
+		assert.Equal(t, tc.status, status, tc.name)
+		assert.Contains(t, body, tc.contains, tc.name)
+	}
+
+	// Tuning is applied before the runner's next record.
+	assert.Eventually(t, func() bool {
+		_, body := do(t, srv.Client(), "GET", srv.URL+"/runners/0", "")
+		var r Runner
+		return json.Unmarshal([]byte(body), &r) == nil && r.Records == 2 && r.Events == 100
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	for _, res := range m.Wait() {
+		assert.NoError(t, res.Error)
+	}
+}
+
+func TestServeSocket(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := runner.NewManager(ctx)
+	socket := filepath.Join(t.TempDir(), "spigot.sock")
+	done := make(chan error)
+	go func() {
+		done <- Serve(ctx, Config{Socket: socket}, m)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	assert.Eventually(t, func() bool {
+		resp, err := client.Get("http://spigot/runners")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		config      map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Address": {
+			config: map[string]interface{}{"address": "localhost:9101"},
+		},
+		"Socket": {
+			config: map[string]interface{}{"socket": "/tmp/spigot.sock"},
+		},
+		"Neither": {
+			config:      map[string]interface{}{},
+			hasError:    true,
+			errorString: "exactly one of 'address' or 'socket' must be set accessing config",
+		},
+		"Both": {
+			config:      map[string]interface{}{"address": "localhost:9101", "socket": "/tmp/spigot.sock"},
+			hasError:    true,
+			errorString: "exactly one of 'address' or 'socket' must be set accessing config",
+		},
+	}
+	for name, tc := range tests {
+		var c Config
+		err := ucfg.MustNewFrom(tc.config).Unpack(&c)
+		if tc.hasError {
+			assert.Error(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.NoError(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Package describe lists the config options of generators and outputs
+// from the "config" tags of their config structs, so users can find
+// them without reading the source.
+package describe
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+)
+
+// Option is a config option of a generator or output.  Default is
+// empty if the default is the zero value.
+type Option struct {
+	Name     string
+	Type     string
+	Default  string
+	Required bool
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	configType   = reflect.TypeOf(ucfg.Config{})
+)
+
+// Options returns the options of cfg, which is a config struct holding
+// its default values.  Fields without a "config" tag and the "type"
+// option are left out.  Options of nested structs are named with their
+// path, for example "retry.max_attempts".
+func Options(cfg interface{}) []Option {
+	if cfg == nil {
+		return nil
+	}
+	v := reflect.Indirect(reflect.ValueOf(cfg))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	return options("", v)
+}
+
+func options(prefix string, v reflect.Value) []Option {
+	var opts []Option
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("config"), ",")
+		if name == "" || (prefix == "" && name == "type") {
+			continue
+		}
+		fv := v.Field(i)
+		ft := f.Type
+		if ft.Kind() == reflect.Struct && ft != configType && ft != durationType {
+			opts = append(opts, options(prefix+name+".", fv)...)
+			continue
+		}
+		opts = append(opts, Option{
+			Name:     prefix + name,
+			Type:     typeName(ft),
+			Default:  defaultValue(fv),
+			Required: strings.Contains(f.Tag.Get("validate"), "required"),
+		})
+	}
+	return opts
+}
+
+func typeName(t reflect.Type) string {
+	if t == durationType {
+		return "duration"
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeName(t.Elem())
+	case reflect.Slice:
+		return "list of " + typeName(t.Elem())
+	case reflect.Map:
+		return "object"
+	case reflect.Struct:
+		return "object"
+	case reflect.Bool:
+		return "bool"
+	case reflect.String:
+		return "string"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	}
+	return t.Kind().String()
+}
+
+func defaultValue(v reflect.Value) string {
+	if v.IsZero() {
+		return ""
+	}
+	if v.Type() == durationType {
+		return time.Duration(v.Int()).String()
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		return defaultValue(v.Elem())
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = defaultValue(v.Index(i))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,78 @@
+package describe
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/leehinman/spigot/pkg/generator"
+	_ "github.com/leehinman/spigot/pkg/include"
+	"github.com/leehinman/spigot/pkg/output"
+	"github.com/stretchr/testify/assert"
+)
+
+type retryConfig struct {
+	MaxAttempts int `config:"max_attempts"`
+}
+
+type testConfig struct {
+	Type      string         `config:"type" validate:"required"`
+	Address   string         `config:"address" validate:"required"`
+	Timeout   time.Duration  `config:"timeout"`
+	Tags      []string       `config:"tags"`
+	Children  []*ucfg.Config `config:"children"`
+	Seed      *int64         `config:"seed"`
+	Rate      float64        `config:"rate"`
+	Retry     retryConfig    `config:"retry"`
+	Untagged  bool
+	Delimiter string `config:"delimiter,ignore"`
+}
+
+func TestOptions(t *testing.T) {
+	seed := int64(7)
+	opts := Options(testConfig{
+		Type:      "test",
+		Address:   "localhost:9000",
+		Timeout:   15 * time.Second,
+		Tags:      []string{"a", "b"},
+		Seed:      &seed,
+		Retry:     retryConfig{MaxAttempts: 5},
+		Delimiter: "\n",
+	})
+	assert.Equal(t, []Option{
+		{Name: "address", Type: "string", Default: `"localhost:9000"`, Required: true},
+		{Name: "timeout", Type: "duration", Default: "15s"},
+		{Name: "tags", Type: "list of string", Default: `["a", "b"]`},
+		{Name: "children", Type: "list of object"},
+		{Name: "seed", Type: "integer", Default: "7"},
+		{Name: "rate", Type: "number"},
+		{Name: "retry.max_attempts", Type: "integer", Default: "5"},
+		{Name: "delimiter", Type: "string", Default: `"\n"`},
+	}, opts)
+	assert.Nil(t, Options(nil))
+}
+
+func TestRegistered(t *testing.T) {
+	for _, name := range generator.Names() {
+		info, err := generator.GetInfo(name)
+		assert.Nil(t, err, name)
+		assert.NotEmpty(t, info.Description, name)
+		example := info.Example
+		if example == nil {
+			example = map[string]interface{}{"type": name}
+		}
+		g, err := generator.New(ucfg.MustNewFrom(example, ucfg.PathSep(".")), rand.New(rand.NewSource(1)), clock.Real)
+		if assert.Nil(t, err, name) {
+			b, err := g.Next()
+			assert.Nil(t, err, name)
+			assert.NotEmpty(t, b, name)
+		}
+	}
+	for _, name := range output.Names() {
+		info, err := output.GetInfo(name)
+		assert.Nil(t, err, name)
+		assert.NotEmpty(t, info.Description, name)
+	}
+}
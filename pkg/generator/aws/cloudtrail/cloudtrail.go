@@ -0,0 +1,710 @@
+// Package cloudtrail generates AWS CloudTrail management and data events.
+//
+// Each generator has a fixed account with a handful of identities, an
+// IAM user has the same access key, source IP address and user agent
+// in every event.  Instances started by RunInstances are the ones
+// stopped by TerminateInstances and objects written by PutObject are
+// the ones read by GetObject and removed by DeleteObject.
+//
+// Configuration:
+//
+//	event_names:      The events to generate, or leave blank for all.
+//	                  Valid values are: ConsoleLogin, AssumeRole,
+//	                  CreateUser, DescribeInstances, RunInstances,
+//	                  TerminateInstances, PutObject, GetObject and
+//	                  DeleteObject.
+//	account_id:       The 12 digit AWS account ID, or leave blank for a
+//	                  random one.
+//	records_per_file: Optional, wraps this many events in a
+//	                  {"Records":[...]} object the way CloudTrail
+//	                  writes them to S3.  The s3 output writes all the
+//	                  records of an interval to one object, so use
+//	                  "records: 1" on the runner to get one file per
+//	                  object for the S3 and SQS input.
+//
+//	- generator:
+//	    type: aws:cloudtrail
+//	    event_names: [ConsoleLogin, AssumeRole, PutObject, GetObject]
+//	    records_per_file: 50
+package cloudtrail
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/google/uuid"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "aws:cloudtrail"
+
+const (
+	eventVersion = "1.08"
+	eventTimeFmt = "2006-01-02T15:04:05Z"
+	// responseTimeFmt is the format of dates in response elements.
+	responseTimeFmt = "Jan 2, 2006, 3:04:05 PM"
+
+	// globalRegion is the region of IAM, STS and console sign in events.
+	globalRegion = "us-east-1"
+
+	// fileWindow is the time the events of one file are spread over,
+	// CloudTrail delivers a file about every five minutes.
+	fileWindow = 5 * time.Minute
+
+	// maxTracked is the most instances or objects that are remembered
+	// for later events.
+	maxTracked = 1024
+
+	// errorRate is the chance an API call is denied.
+	errorRate = 0.05
+	// loginFailureRate is the chance a console sign in fails.
+	loginFailureRate = 0.1
+
+	IdentityIAMUser     = "IAMUser"
+	IdentityAssumedRole = "AssumedRole"
+	IdentityRoot        = "Root"
+
+	EventTypeAPICall       = "AwsApiCall"
+	EventTypeConsoleSignIn = "AwsConsoleSignIn"
+
+	CategoryManagement = "Management"
+	CategoryData       = "Data"
+)
+
+var (
+	// events are the supported events.  Each randomize function fills
+	// in the parts of the record that depend on the event and returns
+	// false if the call is denied.
+	events = [...]event{
+		{name: "ConsoleLogin", source: "signin.amazonaws.com", management: true, randomize: (*Generator).consoleLogin},
+		{name: "AssumeRole", source: "sts.amazonaws.com", management: true, randomize: (*Generator).assumeRole},
+		{name: "CreateUser", source: "iam.amazonaws.com", management: true, randomize: (*Generator).createUser},
+		{name: "DescribeInstances", source: "ec2.amazonaws.com", readOnly: true, management: true, randomize: (*Generator).describeInstances},
+		{name: "RunInstances", source: "ec2.amazonaws.com", management: true, randomize: (*Generator).runInstances},
+		{name: "TerminateInstances", source: "ec2.amazonaws.com", management: true, randomize: (*Generator).terminateInstances},
+		{name: "PutObject", source: "s3.amazonaws.com", randomize: (*Generator).putObject},
+		{name: "GetObject", source: "s3.amazonaws.com", readOnly: true, randomize: (*Generator).getObject},
+		{name: "DeleteObject", source: "s3.amazonaws.com", randomize: (*Generator).deleteObject},
+	}
+	userNames     = [...]string{"alice", "bob", "carol", "dave", "erin"}
+	newUserNames  = [...]string{"build-bot", "auditor", "backup", "deploy", "analyst", "support"}
+	roleNames     = [...]string{"Admin", "ReadOnly", "DeployRole", "DataPipeline"}
+	sessionNames  = [...]string{"ci-session", "console-session", "botocore-session-1697500000", "terraform"}
+	bucketNames   = [...]string{"logs", "backups", "data-lake"}
+	objectPrefix  = [...]string{"reports/", "exports/", "uploads/", "tmp/"}
+	objectSuffix  = [...]string{".csv", ".json", ".gz", ".parquet"}
+	imageIds      = [...]string{"ami-0c02fb55956c7d316", "ami-0b5eea76982371e91", "ami-053b0d53c279acc90", "ami-0a0e5d9c7acc336f1"}
+	instanceTypes = [...]string{"t3.micro", "t3.medium", "m5.large", "c5.xlarge", "r5.2xlarge"}
+	apiAgents     = [...]string{
+		"aws-cli/2.13.25 Python/3.11.5 Linux/5.15.0-86-generic exe/x86_64.ubuntu.22 prompt/off",
+		"aws-cli/2.15.4 Python/3.11.6 Darwin/23.1.0 exe/x86_64 prompt/off",
+		"Boto3/1.28.62 md/Botocore#1.31.62 ua/2.0 os/linux#5.10.197 md/arch#x86_64 lang/python#3.11.6 cfg/retry-mode#legacy Botocore/1.31.62",
+		"aws-sdk-go-v2/1.21.2 os/linux lang/go#1.21.3 md/GOOS#linux md/GOARCH#amd64 api/s3#1.40.2",
+		"APN/1.0 HashiCorp/1.0 Terraform/1.6.2 (+https://www.terraform.io) terraform-provider-aws/5.22.0",
+	}
+	idChars  = []byte("ABCDEFGHIJKLMNOPQRSTUVWXYZ234567")
+	hexChars = []byte("0123456789abcdef")
+)
+
+type event struct {
+	name       string
+	source     string
+	readOnly   bool
+	management bool
+	randomize  func(*Generator, *Record) bool
+}
+
+// SessionIssuer is the role an assumed role session was issued for.
+type SessionIssuer struct {
+	Type        string `json:"type"`
+	PrincipalID string `json:"principalId"`
+	ARN         string `json:"arn"`
+	AccountID   string `json:"accountId"`
+	UserName    string `json:"userName"`
+}
+
+// SessionAttributes are the attributes of a session.
+type SessionAttributes struct {
+	CreationDate     string `json:"creationDate"`
+	MFAAuthenticated string `json:"mfaAuthenticated"`
+}
+
+// SessionContext is the session of an assumed role.
+type SessionContext struct {
+	SessionIssuer SessionIssuer     `json:"sessionIssuer"`
+	Attributes    SessionAttributes `json:"attributes"`
+}
+
+// UserIdentity is the identity that made the request.
+type UserIdentity struct {
+	Type           string          `json:"type"`
+	PrincipalID    string          `json:"principalId"`
+	ARN            string          `json:"arn"`
+	AccountID      string          `json:"accountId"`
+	AccessKeyID    string          `json:"accessKeyId,omitempty"`
+	UserName       string          `json:"userName,omitempty"`
+	SessionContext *SessionContext `json:"sessionContext,omitempty"`
+}
+
+// Resource is a resource accessed by a data event.
+type Resource struct {
+	AccountID string `json:"accountId,omitempty"`
+	Type      string `json:"type"`
+	ARN       string `json:"ARN"`
+}
+
+// Record is a CloudTrail event.
+type Record struct {
+	EventVersion        string                 `json:"eventVersion"`
+	UserIdentity        UserIdentity           `json:"userIdentity"`
+	EventTime           string                 `json:"eventTime"`
+	EventSource         string                 `json:"eventSource"`
+	EventName           string                 `json:"eventName"`
+	AWSRegion           string                 `json:"awsRegion"`
+	SourceIPAddress     string                 `json:"sourceIPAddress"`
+	UserAgent           string                 `json:"userAgent"`
+	ErrorCode           string                 `json:"errorCode,omitempty"`
+	ErrorMessage        string                 `json:"errorMessage,omitempty"`
+	RequestParameters   map[string]interface{} `json:"requestParameters"`
+	ResponseElements    map[string]interface{} `json:"responseElements"`
+	AdditionalEventData map[string]interface{} `json:"additionalEventData,omitempty"`
+	RequestID           string                 `json:"requestID,omitempty"`
+	EventID             string                 `json:"eventID"`
+	ReadOnly            bool                   `json:"readOnly"`
+	Resources           []Resource             `json:"resources,omitempty"`
+	EventType           string                 `json:"eventType"`
+	ManagementEvent     bool                   `json:"managementEvent"`
+	RecipientAccountID  string                 `json:"recipientAccountId"`
+	EventCategory       string                 `json:"eventCategory"`
+}
+
+// identity is a principal of the account with the address and agents
+// it always uses.
+type identity struct {
+	UserIdentity
+	sourceIP     string
+	apiAgent     string
+	browserAgent string
+}
+
+// instance is an EC2 instance started by RunInstances.
+type instance struct {
+	id     string
+	region string
+}
+
+// bucket is an S3 bucket of the account.
+type bucket struct {
+	name   string
+	region string
+}
+
+// object is an S3 object written by PutObject.
+type object struct {
+	bucket bucket
+	key    string
+}
+
+// Generator provides an AWS CloudTrail record generator.
+type Generator struct {
+	events         []event
+	recordsPerFile int
+	accountId      string
+	users          []identity
+	roles          []identity
+	buckets        []bucket
+	instances      []instance
+	objects        []object
+	now            time.Time
+	rnd            *rand.Rand
+	clock          clock.Clock
+}
+
+func init() {
+	_ = generator.Register(Name, New)
+	generator.RegisterInfo(Name, generator.Info{
+		Description: "AWS CloudTrail management and data events as JSON.",
+		Config:      defaultConfig(),
+	})
+}
+
+// New is the factory for AWS CloudTrail objects.
+func New(cfg *ucfg.Config, r *rand.Rand, clk clock.Clock) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	g := Generator{
+		recordsPerFile: c.RecordsPerFile,
+		accountId:      c.AccountId,
+		rnd:            r,
+		clock:          clk,
+	}
+	if len(c.EventNames) == 0 {
+		g.events = events[:]
+	}
+	for _, name := range c.EventNames {
+		e, _ := findEvent(name)
+		g.events = append(g.events, e)
+	}
+	if g.accountId == "" {
+		g.accountId = random.AWSAccountID(r)
+	}
+	g.createIdentities()
+
+	return &g, nil
+}
+
+// eventNames returns the names of the supported events.
+func eventNames() []string {
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = e.name
+	}
+	return names
+}
+
+// findEvent returns the event called name and whether there is one.
+func findEvent(name string) (event, bool) {
+	for _, e := range events {
+		if e.name == name {
+			return e, true
+		}
+	}
+	return event{}, false
+}
+
+// Next produces the next AWS CloudTrail record, or the next file of
+// records if "records_per_file" is set.  The events of a file are in
+// order and spread over the five minutes before now.
+func (g *Generator) Next() ([]byte, error) {
+	now := g.clock.Now().UTC()
+	if g.recordsPerFile == 0 {
+		g.now = now
+		return marshal(g.record())
+	}
+
+	file := struct {
+		Records []Record `json:"Records"`
+	}{
+		Records: make([]Record, 0, g.recordsPerFile),
+	}
+	slot := fileWindow / time.Duration(g.recordsPerFile)
+	start := now.Add(-fileWindow)
+	for i := 0; i < g.recordsPerFile; i++ {
+		g.now = start.Add(slot * time.Duration(i))
+		if slot > 0 {
+			g.now = g.now.Add(time.Duration(g.rnd.Int63n(int64(slot))))
+		}
+		file.Records = append(file.Records, g.record())
+	}
+	return marshal(file)
+}
+
+// marshal encodes v as JSON without escaping HTML characters, as
+// CloudTrail does.
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("unable to marshal %s data: %w", Name, err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// record returns a random event made by one of the identities.
+func (g *Generator) record() Record {
+	e := g.events[g.rnd.Intn(len(g.events))]
+	rec := Record{
+		EventVersion:       eventVersion,
+		EventTime:          g.now.Format(eventTimeFmt),
+		EventSource:        e.source,
+		EventName:          e.name,
+		AWSRegion:          random.AWSRegion(g.rnd),
+		RequestID:          g.uuid(),
+		EventID:            g.uuid(),
+		ReadOnly:           e.readOnly,
+		EventType:          EventTypeAPICall,
+		ManagementEvent:    e.management,
+		RecipientAccountID: g.accountId,
+		EventCategory:      CategoryManagement,
+	}
+	if !e.management {
+		rec.EventCategory = CategoryData
+	}
+	id := g.identity()
+	g.setIdentity(&rec, id)
+	if !e.randomize(g, &rec) {
+		rec.ErrorCode = "AccessDenied"
+		if rec.EventSource == "ec2.amazonaws.com" {
+			rec.ErrorCode = "Client.UnauthorizedOperation"
+		}
+		rec.ErrorMessage = fmt.Sprintf("User: %s is not authorized to perform: %s:%s", rec.UserIdentity.ARN, rec.EventSource[:len(rec.EventSource)-len(".amazonaws.com")], rec.EventName)
+		rec.ResponseElements = nil
+	}
+	return rec
+}
+
+// denied reports if an API call should fail.
+func (g *Generator) denied() bool {
+	return g.rnd.Float64() < errorRate
+}
+
+// identity returns a random user or role session.
+func (g *Generator) identity() identity {
+	n := g.rnd.Intn(len(g.users) + len(g.roles))
+	if n < len(g.users) {
+		return g.users[n]
+	}
+	return g.roles[n-len(g.users)]
+}
+
+func (g *Generator) setIdentity(rec *Record, id identity) {
+	rec.UserIdentity = id.UserIdentity
+	rec.SourceIPAddress = id.sourceIP
+	rec.UserAgent = id.apiAgent
+}
+
+func (g *Generator) consoleLogin(rec *Record) bool {
+	// Console sign in is only done by users.
+	id := g.users[g.rnd.Intn(len(g.users))]
+	g.setIdentity(rec, id)
+	rec.UserAgent = id.browserAgent
+	rec.AWSRegion = globalRegion
+	rec.EventType = EventTypeConsoleSignIn
+	rec.RequestID = ""
+	rec.UserIdentity.AccessKeyID = ""
+	mfa := "No"
+	if id.Type == IdentityRoot || g.rnd.Intn(2) == 0 {
+		mfa = "Yes"
+	}
+	rec.AdditionalEventData = map[string]interface{}{
+		"LoginTo":       "https://console.aws.amazon.com/console/home?state=hashArgs%23&isauthcode=true",
+		"MobileVersion": "No",
+		"MFAUsed":       mfa,
+	}
+	result := "Success"
+	if g.rnd.Float64() < loginFailureRate {
+		result = "Failure"
+		rec.ErrorMessage = "Failed authentication"
+	}
+	rec.ResponseElements = map[string]interface{}{"ConsoleLogin": result}
+	return true
+}
+
+func (g *Generator) assumeRole(rec *Record) bool {
+	rec.AWSRegion = globalRegion
+	role := roleNames[g.rnd.Intn(len(roleNames))]
+	session := sessionNames[g.rnd.Intn(len(sessionNames))]
+	rec.RequestParameters = map[string]interface{}{
+		"roleArn":         g.arn("iam", "role/"+role),
+		"roleSessionName": session,
+		"durationSeconds": 3600,
+	}
+	if g.denied() {
+		return false
+	}
+	roleId := g.id("AROA", 17)
+	rec.ResponseElements = map[string]interface{}{
+		"credentials": map[string]interface{}{
+			"accessKeyId":  g.id("ASIA", 16),
+			"sessionToken": g.id("IQoJb3JpZ2luX2Vj", 64),
+			"expiration":   g.now.Add(time.Hour).Format(responseTimeFmt),
+		},
+		"assumedRoleUser": map[string]interface{}{
+			"assumedRoleId": roleId + ":" + session,
+			"arn":           g.arn("sts", "assumed-role/"+role+"/"+session),
+		},
+	}
+	rec.Resources = []Resource{
+		{AccountID: g.accountId, Type: "AWS::IAM::Role", ARN: g.arn("iam", "role/"+role)},
+	}
+	return true
+}
+
+func (g *Generator) createUser(rec *Record) bool {
+	rec.AWSRegion = globalRegion
+	name := newUserNames[g.rnd.Intn(len(newUserNames))]
+	rec.RequestParameters = map[string]interface{}{"userName": name}
+	if g.denied() {
+		return false
+	}
+	rec.ResponseElements = map[string]interface{}{
+		"user": map[string]interface{}{
+			"path":       "/",
+			"userName":   name,
+			"userId":     g.id("AIDA", 17),
+			"arn":        g.arn("iam", "user/"+name),
+			"createDate": g.now.Format(responseTimeFmt),
+		},
+	}
+	return true
+}
+
+func (g *Generator) describeInstances(rec *Record) bool {
+	rec.RequestParameters = map[string]interface{}{
+		"instancesSet": map[string]interface{}{},
+		"filterSet":    map[string]interface{}{},
+	}
+	rec.ResponseElements = nil
+	return !g.denied()
+}
+
+func (g *Generator) runInstances(rec *Record) bool {
+	image := imageIds[g.rnd.Intn(len(imageIds))]
+	instanceType := instanceTypes[g.rnd.Intn(len(instanceTypes))]
+	rec.RequestParameters = map[string]interface{}{
+		"instancesSet": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"imageId": image, "minCount": 1, "maxCount": 1},
+			},
+		},
+		"instanceType":                      instanceType,
+		"blockDeviceMapping":                map[string]interface{}{},
+		"monitoring":                        map[string]interface{}{"enabled": false},
+		"disableApiTermination":             false,
+		"disableApiStop":                    false,
+		"clientToken":                       g.uuid(),
+		"instanceInitiatedShutdownBehavior": "stop",
+	}
+	if g.denied() {
+		return false
+	}
+	inst := instance{id: "i-" + g.hex(17), region: rec.AWSRegion}
+	g.instances = append(g.instances, inst)
+	if len(g.instances) > maxTracked {
+		g.instances = g.instances[1:]
+	}
+	rec.ResponseElements = map[string]interface{}{
+		"requestId":     g.uuid(),
+		"reservationId": "r-" + g.hex(17),
+		"ownerId":       g.accountId,
+		"instancesSet": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{
+					"instanceId":       inst.id,
+					"imageId":          image,
+					"instanceType":     instanceType,
+					"instanceState":    map[string]interface{}{"code": 0, "name": "pending"},
+					"privateIpAddress": fmt.Sprintf("10.%d.%d.%d", g.rnd.Intn(256), g.rnd.Intn(256), 1+g.rnd.Intn(254)),
+					"placement": map[string]interface{}{
+						"availabilityZone": random.AWSAvailabilityZoneInRegion(g.rnd, inst.region),
+						"tenancy":          "default",
+					},
+					"launchTime": g.now.UnixMilli(),
+				},
+			},
+		},
+	}
+	return true
+}
+
+func (g *Generator) terminateInstances(rec *Record) bool {
+	inst := instance{id: "i-" + g.hex(17), region: rec.AWSRegion}
+	if len(g.instances) > 0 {
+		n := g.rnd.Intn(len(g.instances))
+		inst = g.instances[n]
+		g.instances = append(g.instances[:n], g.instances[n+1:]...)
+	}
+	rec.AWSRegion = inst.region
+	rec.RequestParameters = map[string]interface{}{
+		"instancesSet": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{"instanceId": inst.id},
+			},
+		},
+	}
+	if g.denied() {
+		return false
+	}
+	rec.ResponseElements = map[string]interface{}{
+		"requestId": g.uuid(),
+		"instancesSet": map[string]interface{}{
+			"items": []interface{}{
+				map[string]interface{}{
+					"instanceId":    inst.id,
+					"currentState":  map[string]interface{}{"code": 32, "name": "shutting-down"},
+					"previousState": map[string]interface{}{"code": 16, "name": "running"},
+				},
+			},
+		},
+	}
+	return true
+}
+
+func (g *Generator) putObject(rec *Record) bool {
+	b := g.buckets[g.rnd.Intn(len(g.buckets))]
+	o := object{
+		bucket: b,
+		key:    objectPrefix[g.rnd.Intn(len(objectPrefix))] + g.now.Format("2006/01/02/") + g.uuid() + objectSuffix[g.rnd.Intn(len(objectSuffix))],
+	}
+	g.s3(rec, o, g.rnd.Intn(1<<24), 0)
+	rec.ResponseElements = map[string]interface{}{"x-amz-server-side-encryption": "AES256"}
+	if g.denied() {
+		return false
+	}
+	g.objects = append(g.objects, o)
+	if len(g.objects) > maxTracked {
+		g.objects = g.objects[1:]
+	}
+	return true
+}
+
+func (g *Generator) getObject(rec *Record) bool {
+	o := g.object(false)
+	g.s3(rec, o, 0, g.rnd.Intn(1<<24))
+	return !g.denied()
+}
+
+func (g *Generator) deleteObject(rec *Record) bool {
+	o := g.object(true)
+	g.s3(rec, o, 0, 0)
+	return !g.denied()
+}
+
+// object returns one of the objects that were written, removing it
+// if remove is true, or a new one if there are none.
+func (g *Generator) object(remove bool) object {
+	if len(g.objects) == 0 {
+		b := g.buckets[g.rnd.Intn(len(g.buckets))]
+		return object{bucket: b, key: objectPrefix[g.rnd.Intn(len(objectPrefix))] + g.uuid() + objectSuffix[g.rnd.Intn(len(objectSuffix))]}
+	}
+	n := g.rnd.Intn(len(g.objects))
+	o := g.objects[n]
+	if remove {
+		g.objects = append(g.objects[:n], g.objects[n+1:]...)
+	}
+	return o
+}
+
+// s3 fills in the fields common to S3 data events.
+func (g *Generator) s3(rec *Record, o object, in, out int) {
+	rec.AWSRegion = o.bucket.region
+	rec.RequestID = strings.ToUpper(g.hex(16))
+	rec.RequestParameters = map[string]interface{}{
+		"bucketName": o.bucket.name,
+		"Host":       fmt.Sprintf("%s.s3.%s.amazonaws.com", o.bucket.name, o.bucket.region),
+		"key":        o.key,
+	}
+	rec.ResponseElements = nil
+	rec.AdditionalEventData = map[string]interface{}{
+		"SignatureVersion":     "SigV4",
+		"CipherSuite":          "TLS_AES_128_GCM_SHA256",
+		"bytesTransferredIn":   in,
+		"bytesTransferredOut":  out,
+		"AuthenticationMethod": "AuthHeader",
+		"x-amz-id-2":           g.id("", 76),
+	}
+	rec.Resources = []Resource{
+		{Type: "AWS::S3::Object", ARN: "arn:aws:s3:::" + o.bucket.name + "/" + o.key},
+		{AccountID: g.accountId, Type: "AWS::S3::Bucket", ARN: "arn:aws:s3:::" + o.bucket.name},
+	}
+}
+
+// createIdentities creates the root user, the IAM users, the role
+// sessions and the buckets of the account.
+func (g *Generator) createIdentities() {
+	root := identity{
+		UserIdentity: UserIdentity{
+			Type:        IdentityRoot,
+			PrincipalID: g.accountId,
+			ARN:         g.arn("iam", "root"),
+			AccountID:   g.accountId,
+			AccessKeyID: g.id("AKIA", 16),
+		},
+	}
+	g.users = append(g.users, g.agents(root))
+	for _, name := range userNames {
+		user := identity{
+			UserIdentity: UserIdentity{
+				Type:        IdentityIAMUser,
+				PrincipalID: g.id("AIDA", 17),
+				ARN:         g.arn("iam", "user/"+name),
+				AccountID:   g.accountId,
+				AccessKeyID: g.id("AKIA", 16),
+				UserName:    name,
+			},
+		}
+		g.users = append(g.users, g.agents(user))
+	}
+	created := g.clock.Now().UTC().Add(-time.Duration(g.rnd.Intn(3600)) * time.Second).Format(eventTimeFmt)
+	for _, name := range roleNames {
+		roleId := g.id("AROA", 17)
+		session := sessionNames[g.rnd.Intn(len(sessionNames))]
+		role := identity{
+			UserIdentity: UserIdentity{
+				Type:        IdentityAssumedRole,
+				PrincipalID: roleId + ":" + session,
+				ARN:         g.arn("sts", "assumed-role/"+name+"/"+session),
+				AccountID:   g.accountId,
+				AccessKeyID: g.id("ASIA", 16),
+				SessionContext: &SessionContext{
+					SessionIssuer: SessionIssuer{
+						Type:        "Role",
+						PrincipalID: roleId,
+						ARN:         g.arn("iam", "role/"+name),
+						AccountID:   g.accountId,
+						UserName:    name,
+					},
+					Attributes: SessionAttributes{
+						CreationDate:     created,
+						MFAAuthenticated: "false",
+					},
+				},
+			},
+		}
+		g.roles = append(g.roles, g.agents(role))
+	}
+	for _, name := range bucketNames {
+		g.buckets = append(g.buckets, bucket{name: g.accountId + "-" + name, region: random.AWSRegion(g.rnd)})
+	}
+}
+
+// agents sets the source address and user agents of the identity.
+func (g *Generator) agents(id identity) identity {
+	id.sourceIP = random.IPv4(g.rnd).String()
+	id.apiAgent = apiAgents[g.rnd.Intn(len(apiAgents))]
+	id.browserAgent = random.UserAgent(g.rnd)
+	return id
+}
+
+// arn returns the ARN of a global resource of the account.
+func (g *Generator) arn(service, resource string) string {
+	return fmt.Sprintf("arn:aws:%s::%s:%s", service, g.accountId, resource)
+}
+
+// id returns an ID in the style of IAM unique IDs and access keys.
+func (g *Generator) id(prefix string, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = idChars[g.rnd.Intn(len(idChars))]
+	}
+	return prefix + string(b)
+}
+
+// hex returns n random lower case hex digits, as in EC2 IDs.
+func (g *Generator) hex(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = hexChars[g.rnd.Intn(len(hexChars))]
+	}
+	return string(b)
+}
+
+func (g *Generator) uuid() string {
+	u, err := uuid.NewRandomFromReader(g.rnd)
+	if err != nil {
+		return uuid.Nil.String()
+	}
+	return u.String()
+}
@@ -0,0 +1,127 @@
+package cloudtrail
+
+import (
+	"encoding/json"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "1970-01-02T03:04:05Z")
+	assert.Nil(t, err)
+	for _, e := range events {
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "event_names": []string{e.name}, "account_id": "123456789012"})
+		assert.Nil(t, err, e.name)
+		g, err := New(c, rand.New(rand.NewSource(1)), clock.Fixed(testTime))
+		assert.Nil(t, err, e.name)
+		for i := 0; i < 20; i++ {
+			data, err := g.Next()
+			assert.Nil(t, err, e.name)
+			var got Record
+			assert.Nil(t, json.Unmarshal(data, &got), e.name)
+			assert.Equal(t, e.name, got.EventName, e.name)
+			assert.Equal(t, e.source, got.EventSource, e.name)
+			assert.Equal(t, e.readOnly, got.ReadOnly, e.name)
+			assert.Equal(t, e.management, got.ManagementEvent, e.name)
+			assert.Equal(t, "1970-01-02T03:04:05Z", got.EventTime, e.name)
+			assert.Equal(t, "123456789012", got.UserIdentity.AccountID, e.name)
+			assert.Equal(t, "123456789012", got.RecipientAccountID, e.name)
+			assert.NotEmpty(t, got.AWSRegion, e.name)
+			assert.NotEmpty(t, got.SourceIPAddress, e.name)
+			assert.NotEmpty(t, got.EventID, e.name)
+		}
+	}
+}
+
+func TestIdentities(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "event_names": []string{"DescribeInstances", "CreateUser"}})
+	assert.Nil(t, err)
+	g, err := New(c, rand.New(rand.NewSource(1)), clock.Real)
+	assert.Nil(t, err)
+
+	// sources maps the ARN of each identity to its source address.
+	sources := make(map[string]string)
+	for i := 0; i < 500; i++ {
+		data, err := g.Next()
+		assert.Nil(t, err)
+		var got Record
+		assert.Nil(t, json.Unmarshal(data, &got))
+		if s, ok := sources[got.UserIdentity.ARN]; ok {
+			assert.Equal(t, s, got.SourceIPAddress, got.UserIdentity.ARN)
+		}
+		sources[got.UserIdentity.ARN] = got.SourceIPAddress
+	}
+	assert.Len(t, sources, 1+len(userNames)+len(roleNames))
+}
+
+func TestInstances(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "event_names": []string{"RunInstances", "TerminateInstances"}})
+	assert.Nil(t, err)
+	g, err := New(c, rand.New(rand.NewSource(1)), clock.Real)
+	assert.Nil(t, err)
+
+	// running maps the ID of each instance that was started to its
+	// region.
+	running := make(map[string]string)
+	var terminated int
+	for i := 0; i < 500; i++ {
+		data, err := g.Next()
+		assert.Nil(t, err)
+		var got Record
+		assert.Nil(t, json.Unmarshal(data, &got))
+		if got.ErrorCode != "" {
+			continue
+		}
+		items := got.ResponseElements["instancesSet"].(map[string]interface{})["items"].([]interface{})
+		id := items[0].(map[string]interface{})["instanceId"].(string)
+		assert.Regexp(t, `^i-[0-9a-f]{17}$`, id)
+		if got.EventName == "RunInstances" {
+			running[id] = got.AWSRegion
+			continue
+		}
+		if region, ok := running[id]; ok {
+			assert.Equal(t, region, got.AWSRegion, id)
+			delete(running, id)
+			terminated++
+		}
+	}
+	assert.Greater(t, terminated, 150)
+}
+
+func TestRecordsPerFile(t *testing.T) {
+	testTime, err := time.Parse(time.RFC3339, "1970-01-02T03:04:05Z")
+	assert.Nil(t, err)
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "records_per_file": 25})
+	assert.Nil(t, err)
+	g, err := New(c, rand.New(rand.NewSource(1)), clock.Fixed(testTime))
+	assert.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		data, err := g.Next()
+		assert.Nil(t, err)
+		var got struct {
+			Records []Record
+		}
+		assert.Nil(t, json.Unmarshal(data, &got))
+		assert.Len(t, got.Records, 25)
+
+		// The events are in order and spread over the file's window.
+		times := make(map[string]bool)
+		var last time.Time
+		for _, r := range got.Records {
+			assert.NotEmpty(t, r.EventName)
+			ts, err := time.Parse(time.RFC3339, r.EventTime)
+			assert.Nil(t, err)
+			assert.False(t, ts.Before(last), r.EventTime)
+			assert.False(t, ts.Before(testTime.Add(-fileWindow)), r.EventTime)
+			assert.True(t, ts.Before(testTime), r.EventTime)
+			last = ts
+			times[r.EventTime] = true
+		}
+		assert.Greater(t, len(times), 20)
+	}
+}
@@ -0,0 +1,39 @@
+package cloudtrail
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+type config struct {
+	Type           string   `config:"type" validate:"required"`
+	EventNames     []string `config:"event_names"`
+	AccountId      string   `config:"account_id"`
+	RecordsPerFile int      `config:"records_per_file"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type: Name,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	for _, name := range c.EventNames {
+		if _, ok := findEvent(name); !ok {
+			return fmt.Errorf("'%s' is not a valid value for 'event_names' expected one of %s", name, strings.Join(eventNames(), ", "))
+		}
+	}
+	if c.AccountId != "" && !random.ValidAWSAccountID(c.AccountId) {
+		return fmt.Errorf("'%s' is not a valid value for 'account_id' expected 12 digits", c.AccountId)
+	}
+	if c.RecordsPerFile < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'records_per_file' expected >= 0", c.RecordsPerFile)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package cloudtrail
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'aws:cloudtrail' accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+		"Event Names": {
+			c:           map[string]interface{}{"type": Name, "event_names": []string{"ConsoleLogin", "PutObject"}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Event Name": {
+			c:           map[string]interface{}{"type": Name, "event_names": []string{"ConsoleLogin", "ListBuckets"}},
+			hasError:    true,
+			errorString: "'ListBuckets' is not a valid value for 'event_names' expected one of " + strings.Join(eventNames(), ", ") + " accessing config",
+		},
+		"Account ID": {
+			c:           map[string]interface{}{"type": Name, "account_id": "123456789012"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Account ID": {
+			c:           map[string]interface{}{"type": Name, "account_id": "12345"},
+			hasError:    true,
+			errorString: "'12345' is not a valid value for 'account_id' expected 12 digits accessing config",
+		},
+		"Records Per File": {
+			c:           map[string]interface{}{"type": Name, "records_per_file": 100},
+			hasError:    false,
+			errorString: "",
+		},
+		"Negative Records Per File": {
+			c:           map[string]interface{}{"type": Name, "records_per_file": -1},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'records_per_file' expected >= 0 accessing config",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c, rand.New(rand.NewSource(1)), clock.Real)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, err.Error(), tc.errorString, name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
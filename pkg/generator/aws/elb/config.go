@@ -0,0 +1,35 @@
+package elb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+type config struct {
+	Type      string `config:"type" validate:"required"`
+	Variant   string `config:"variant"`
+	AccountId string `config:"account_id"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:      Name,
+		Variant:   VariantApplication,
+		AccountId: "123456789012",
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	if _, ok := templates[c.Variant]; !ok {
+		return fmt.Errorf("'%s' is not a valid value for 'variant' expected one of %s", c.Variant, strings.Join(variants[:], ", "))
+	}
+	if !random.ValidAWSAccountID(c.AccountId) {
+		return fmt.Errorf("'%s' is not a valid value for 'account_id' expected 12 digits", c.AccountId)
+	}
+	return nil
+}
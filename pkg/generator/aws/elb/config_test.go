@@ -0,0 +1,76 @@
+package elb
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'aws:elb' accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+		"Classic": {
+			c:           map[string]interface{}{"type": Name, "variant": "classic"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Network": {
+			c:           map[string]interface{}{"type": Name, "variant": "network"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Variant": {
+			c:           map[string]interface{}{"type": Name, "variant": "gateway"},
+			hasError:    true,
+			errorString: "'gateway' is not a valid value for 'variant' expected one of classic, application, network accessing config",
+		},
+		"Account Id": {
+			c:           map[string]interface{}{"type": Name, "account_id": "210987654321"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Short Account Id": {
+			c:           map[string]interface{}{"type": Name, "account_id": "1234"},
+			hasError:    true,
+			errorString: "'1234' is not a valid value for 'account_id' expected 12 digits accessing config",
+		},
+		"Invalid Account Id": {
+			c:           map[string]interface{}{"type": Name, "account_id": "12345678901x"},
+			hasError:    true,
+			errorString: "'12345678901x' is not a valid value for 'account_id' expected 12 digits accessing config",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c, rand.New(rand.NewSource(1)), clock.Real)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, err.Error(), tc.errorString, name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,369 @@
+// Package elb generates AWS Elastic Load Balancing access logs for
+// classic, application and network load balancers.
+//
+// Configuration:
+//
+//	variant:    The kind of load balancer, "classic", "application"
+//	            (default) or "network".
+//	account_id: The 12 digit AWS account ID used in the target group
+//	            and certificate ARNs, default 123456789012.
+//
+//	- generator:
+//	    type: "aws:elb"
+//	    variant: application
+//	    account_id: "123456789012"
+package elb
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/google/uuid"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "aws:elb"
+
+const (
+	VariantClassic     = "classic"
+	VariantApplication = "application"
+	VariantNetwork     = "network"
+)
+
+var (
+	classicTemplate     = "{{.Timestamp.Format \"2006-01-02T15:04:05.000000Z\"}} {{.Name}} {{.ClientIp}}:{{.ClientPort}} {{.Target}} {{seconds .RequestProcessingTime 6}} {{seconds .TargetProcessingTime 6}} {{seconds .ResponseProcessingTime 6}} {{.ElbStatusCode}} {{.TargetStatusCode}} {{.ReceivedBytes}} {{.SentBytes}} \"{{.Request}}\" \"{{.UserAgent}}\" {{.SslCipher}} {{.SslProtocol}}"
+	applicationTemplate = "{{.Type}} {{.Timestamp.Format \"2006-01-02T15:04:05.000000Z\"}} {{.Name}} {{.ClientIp}}:{{.ClientPort}} {{.Target}} {{seconds .RequestProcessingTime 3}} {{seconds .TargetProcessingTime 3}} {{seconds .ResponseProcessingTime 3}} {{.ElbStatusCode}} {{.TargetStatusCode}} {{.ReceivedBytes}} {{.SentBytes}} \"{{.Request}}\" \"{{.UserAgent}}\" {{.SslCipher}} {{.SslProtocol}} {{.TargetGroupArn}} \"{{.TraceId}}\" \"{{.DomainName}}\" \"{{.CertArn}}\" {{.MatchedRulePriority}} {{.RequestCreationTime.Format \"2006-01-02T15:04:05.000000Z\"}} \"{{.ActionsExecuted}}\" \"{{.RedirectUrl}}\" \"{{.ErrorReason}}\" \"{{.Target}}\" \"{{.TargetStatusCode}}\" \"{{.Classification}}\" \"{{.ClassificationReason}}\""
+	networkTemplate     = "tls 2.0 {{.Timestamp.Format \"2006-01-02T15:04:05\"}} {{.Name}} {{.ListenerId}} {{.ClientIp}}:{{.ClientPort}} {{.Target}} {{.ConnectionTime}} {{.TlsHandshakeTime}} {{.ReceivedBytes}} {{.SentBytes}} {{.IncomingTlsAlert}} {{.CertArn}} - {{.SslCipher}} {{.SslProtocol}} - {{.DomainName}} {{.AlpnFrontend}} {{.AlpnBackend}} {{.AlpnClientPreference}} {{.ConnectionCreationTime.Format \"2006-01-02T15:04:05\"}}"
+
+	templates = map[string]string{
+		VariantClassic:     classicTemplate,
+		VariantApplication: applicationTemplate,
+		VariantNetwork:     networkTemplate,
+	}
+	variants = [...]string{VariantClassic, VariantApplication, VariantNetwork}
+
+	names   = [...]string{"my-loadbalancer", "web-frontend", "api-gateway", "internal-services"}
+	domains = [...]string{"www.example.com", "api.example.com", "shop.example.com", "static.example.com"}
+	paths   = [...]string{"/", "/index.html", "/login", "/api/v1/users", "/api/v1/orders?page=2", "/images/logo.png", "/health"}
+	// tlsVersions are pairs of protocol and cipher.
+	tlsVersions = [...][2]string{
+		{"TLSv1.2", "ECDHE-RSA-AES128-GCM-SHA256"},
+		{"TLSv1.2", "ECDHE-RSA-AES256-GCM-SHA384"},
+		{"TLSv1.3", "TLS_AES_128_GCM_SHA256"},
+		{"TLSv1.3", "TLS_AES_256_GCM_SHA384"},
+	}
+	// actions are the actions an application load balancer rule can
+	// take.
+	actions         = [...]string{"forward", "forward", "forward", "forward", "authenticate,forward", "waf,forward", "redirect", "fixed-response"}
+	errorReasons    = map[int]string{502: "TargetConnectionErrorCode", 503: "AWSALBTGCookieInvalid", 504: "TargetResponseTimeout"}
+	classifications = [...][2]string{
+		{"Acceptable", "NonCompliantHeader"},
+		{"Ambiguous", "UndefinedContentLengthSemantics"},
+		{"Severe", "AmbiguousUri"},
+	}
+	alpnPreferences = [...]string{"-", "h2,http/1.1", "http/1.1"}
+)
+
+// Elb holds the random fields for a load balancer access log record.
+type Elb struct {
+	Type                   string
+	Timestamp              time.Time
+	Name                   string
+	ClientIp               net.IP
+	ClientPort             int
+	Target                 string
+	RequestProcessingTime  float64
+	TargetProcessingTime   float64
+	ResponseProcessingTime float64
+	ElbStatusCode          string
+	TargetStatusCode       string
+	ReceivedBytes          int
+	SentBytes              int
+	Request                string
+	UserAgent              string
+	SslCipher              string
+	SslProtocol            string
+	TargetGroupArn         string
+	TraceId                string
+	DomainName             string
+	CertArn                string
+	MatchedRulePriority    string
+	RequestCreationTime    time.Time
+	ActionsExecuted        string
+	RedirectUrl            string
+	ErrorReason            string
+	Classification         string
+	ClassificationReason   string
+	ListenerId             string
+	ConnectionTime         int
+	TlsHandshakeTime       string
+	IncomingTlsAlert       string
+	AlpnFrontend           string
+	AlpnBackend            string
+	AlpnClientPreference   string
+	ConnectionCreationTime time.Time
+
+	variant     string
+	accountId   string
+	region      string
+	targetGroup string
+	cert        string
+	template    *template.Template
+	rnd         *rand.Rand
+	clock       clock.Clock
+}
+
+func init() {
+	generator.Register(Name, New)
+	generator.RegisterInfo(Name, generator.Info{
+		Description: "AWS classic, application and network load balancer access logs.",
+		Config:      defaultConfig(),
+	})
+}
+
+// New is the Factory for Elb objects.
+func New(cfg *ucfg.Config, r *rand.Rand, clk clock.Clock) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	e := &Elb{variant: c.Variant, accountId: c.AccountId, rnd: r, clock: clk}
+
+	t, err := template.New(c.Variant).Funcs(generator.FunctionMap).Funcs(template.FuncMap{"seconds": seconds}).Parse(templates[c.Variant])
+	if err != nil {
+		return nil, err
+	}
+	e.template = t
+	e.setup()
+	e.randomize()
+
+	return e, nil
+}
+
+// seconds formats a processing time with the given precision, -1
+// means the time is not known.
+func seconds(s float64, precision int) string {
+	if s < 0 {
+		return "-1"
+	}
+	return strconv.FormatFloat(s, 'f', precision, 64)
+}
+
+// Next produces the next load balancer access log record.
+//
+// Example:
+//
+//	https 1970-01-02T03:04:05.000000Z app/my-loadbalancer/50dc6c495c0c9188 192.168.131.39:2817 10.0.0.1:80 0.086 0.048 0.037 200 200 0 57 "GET https://www.example.com:443/ HTTP/1.1" "curl/7.46.0" ECDHE-RSA-AES128-GCM-SHA256 TLSv1.2 arn:aws:elasticloadbalancing:us-east-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067 "Root=1-58337281-1d84f3d73c47ec4e58577259" "www.example.com" "arn:aws:acm:us-east-2:123456789012:certificate/12345678-1234-1234-1234-123456789012" 1 1970-01-02T03:04:04.815000Z "forward" "-" "-" "10.0.0.1:80" "200" "-" "-"
+func (e *Elb) Next() ([]byte, error) {
+	var buf bytes.Buffer
+
+	err := e.template.Execute(&buf, e)
+	if err != nil {
+		return nil, err
+	}
+
+	e.randomize()
+
+	return buf.Bytes(), err
+}
+
+// setup picks the load balancer, its region, target group and
+// certificate, which are the same for every record.
+func (e *Elb) setup() {
+	name := names[e.rnd.Intn(len(names))]
+	e.region = random.AWSRegion(e.rnd)
+	switch e.variant {
+	case VariantClassic:
+		e.Name = name
+	case VariantApplication:
+		e.Name = fmt.Sprintf("app/%s/%s", name, e.hex(16))
+	case VariantNetwork:
+		e.Name = fmt.Sprintf("net/%s/%s", name, e.hex(16))
+		e.ListenerId = e.hex(16)
+	}
+	e.targetGroup = fmt.Sprintf("arn:aws:elasticloadbalancing:%s:%s:targetgroup/%s-targets/%s", e.region, e.accountId, name, e.hex(16))
+	e.cert = fmt.Sprintf("arn:aws:acm:%s:%s:certificate/%s", e.region, e.accountId, e.uuid())
+	e.CertArn = e.cert
+}
+
+func (e *Elb) randomize() {
+	e.Timestamp = e.clock.Now().UTC()
+	e.ClientIp = random.IPv4(e.rnd)
+	e.ClientPort = random.Port(e.rnd)
+	e.Target = fmt.Sprintf("10.%d.%d.%d:%d", e.rnd.Intn(256), e.rnd.Intn(256), 1+e.rnd.Intn(254), []int{80, 8080, 443}[e.rnd.Intn(3)])
+	e.DomainName = domains[e.rnd.Intn(len(domains))]
+	e.UserAgent = random.UserAgent(e.rnd)
+	e.ReceivedBytes = e.rnd.Intn(4096)
+	e.SentBytes = e.rnd.Intn(1 << 20)
+	tls := tlsVersions[e.rnd.Intn(len(tlsVersions))]
+	e.SslProtocol, e.SslCipher = tls[0], tls[1]
+
+	switch e.variant {
+	case VariantClassic:
+		e.randomizeClassic()
+	case VariantApplication:
+		e.randomizeApplication()
+	case VariantNetwork:
+		e.randomizeNetwork()
+	}
+}
+
+// randomizeHTTP sets the request and the status codes of an HTTP
+// request sent to a target, which are the same for classic and
+// application load balancers.
+func (e *Elb) randomizeHTTP(scheme string, port int, version string) {
+	e.Request = fmt.Sprintf("%s %s://%s:%d%s %s", random.HTTPMethod(e.rnd), scheme, e.DomainName, port, paths[e.rnd.Intn(len(paths))], version)
+	e.RequestProcessingTime = float64(e.rnd.Intn(100)) / 100000
+	e.TargetProcessingTime = float64(e.rnd.Intn(2000)) / 1000
+	e.ResponseProcessingTime = float64(e.rnd.Intn(100)) / 100000
+	status := random.HTTPStatus(e.rnd)
+	e.ElbStatusCode = strconv.Itoa(status)
+	e.TargetStatusCode = e.ElbStatusCode
+	if _, ok := errorReasons[status]; ok {
+		// The load balancer could not get a response from the target.
+		e.Target = "-"
+		e.TargetProcessingTime = -1
+		e.ResponseProcessingTime = -1
+		e.TargetStatusCode = "-"
+	}
+}
+
+func (e *Elb) randomizeClassic() {
+	switch e.rnd.Intn(3) {
+	case 0:
+		// TCP listener
+		e.Request = "- - - "
+		e.UserAgent = "-"
+		e.RequestProcessingTime = float64(e.rnd.Intn(100)) / 100000
+		e.TargetProcessingTime = float64(e.rnd.Intn(100)) / 100000
+		e.ResponseProcessingTime = float64(e.rnd.Intn(100)) / 100000
+		e.ElbStatusCode = "-"
+		e.TargetStatusCode = "-"
+		e.SslCipher = "-"
+		e.SslProtocol = "-"
+	case 1:
+		e.randomizeHTTP("http", 80, "HTTP/1.1")
+		e.SslCipher = "-"
+		e.SslProtocol = "-"
+	default:
+		e.randomizeHTTP("https", 443, "HTTP/1.1")
+	}
+}
+
+func (e *Elb) randomizeApplication() {
+	version := random.HTTPVersion(e.rnd)
+	switch {
+	case version == "HTTP/2":
+		e.Type = "h2"
+		e.randomizeHTTP("https", 443, "HTTP/2.0")
+	case e.rnd.Intn(2) == 0:
+		e.Type = "http"
+		e.randomizeHTTP("http", 80, version)
+		e.SslCipher = "-"
+		e.SslProtocol = "-"
+	default:
+		e.Type = "https"
+		e.randomizeHTTP("https", 443, version)
+	}
+	e.CertArn = e.cert
+	if e.Type == "http" {
+		e.CertArn = "-"
+	}
+
+	e.TargetGroupArn = e.targetGroup
+	e.TraceId = fmt.Sprintf("Root=1-%08x-%s", e.Timestamp.Unix(), e.hex(24))
+	e.MatchedRulePriority = strconv.Itoa(e.rnd.Intn(50))
+	e.ActionsExecuted = actions[e.rnd.Intn(len(actions))]
+	e.RedirectUrl = "-"
+	e.ErrorReason = "-"
+	e.Classification = "-"
+	e.ClassificationReason = "-"
+
+	switch e.ActionsExecuted {
+	case "redirect":
+		e.ElbStatusCode = "301"
+		e.RedirectUrl = fmt.Sprintf("https://%s:443%s", e.DomainName, paths[e.rnd.Intn(len(paths))])
+		e.noTarget()
+	case "fixed-response":
+		e.ElbStatusCode = []string{"200", "404", "503"}[e.rnd.Intn(3)]
+		e.noTarget()
+	default:
+		if e.Target == "-" {
+			status, _ := strconv.Atoi(e.ElbStatusCode)
+			e.ErrorReason = errorReasons[status]
+			e.TargetGroupArn = "-"
+		}
+	}
+	if e.rnd.Intn(20) == 0 {
+		c := classifications[e.rnd.Intn(len(classifications))]
+		e.Classification, e.ClassificationReason = c[0], c[1]
+	}
+	total := e.RequestProcessingTime
+	if e.TargetProcessingTime > 0 {
+		total += e.TargetProcessingTime
+	}
+	e.RequestCreationTime = e.Timestamp.Add(-time.Duration(total * float64(time.Second))).Truncate(time.Millisecond)
+}
+
+// noTarget clears the target of a request the load balancer answered
+// itself.
+func (e *Elb) noTarget() {
+	e.Target = "-"
+	e.TargetStatusCode = "-"
+	e.TargetGroupArn = "-"
+	e.TargetProcessingTime = -1
+	e.ResponseProcessingTime = float64(e.rnd.Intn(100)) / 100000
+}
+
+func (e *Elb) randomizeNetwork() {
+	e.Target = fmt.Sprintf("%s:443", random.IPv4(e.rnd))
+	e.ConnectionTime = e.rnd.Intn(60000)
+	e.TlsHandshakeTime = strconv.Itoa(e.rnd.Intn(100))
+	e.IncomingTlsAlert = "-"
+	if e.rnd.Intn(20) == 0 {
+		// The client closed the connection during the handshake.
+		e.TlsHandshakeTime = "-"
+		e.IncomingTlsAlert = "0x2e"
+		e.SentBytes = 0
+	}
+	e.SslProtocol = strings.ToLower(strings.ReplaceAll(e.SslProtocol, ".", ""))
+	e.AlpnClientPreference = alpnPreferences[e.rnd.Intn(len(alpnPreferences))]
+	e.AlpnFrontend = "-"
+	e.AlpnBackend = "-"
+	if e.AlpnClientPreference != "-" {
+		e.AlpnFrontend = strings.SplitN(e.AlpnClientPreference, ",", 2)[0]
+		e.AlpnBackend = e.AlpnFrontend
+	}
+	if e.rnd.Intn(10) == 0 {
+		e.DomainName = "-"
+	}
+	e.ConnectionCreationTime = e.Timestamp.Add(-time.Duration(e.ConnectionTime) * time.Millisecond)
+}
+
+// hex returns n random lower case hex digits.
+func (e *Elb) hex(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = "0123456789abcdef"[e.rnd.Intn(16)]
+	}
+	return string(b)
+}
+
+func (e *Elb) uuid() string {
+	u, err := uuid.NewRandomFromReader(e.rnd)
+	if err != nil {
+		return uuid.Nil.String()
+	}
+	return u.String()
+}
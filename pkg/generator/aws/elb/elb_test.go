@@ -0,0 +1,62 @@
+package elb
+
+import (
+	"math/rand"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		variant  string
+		expected string
+	}{
+		"Classic": {variant: VariantClassic,
+			expected: "1970-01-02T03:04:05.000000Z web-frontend 181.17.98.92:53864 - 0.000560 -1 -1 502 - 2039 822069 \"DELETE https://www.example.com:443/api/v1/users HTTP/1.1\" \"Mozilla/5.0 (iPhone; CPU iPhone OS 12_3 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) FxiOS/98.0 Mobile/15E148 Safari/605.1.15\" ECDHE-RSA-AES128-GCM-SHA256 TLSv1.2"},
+		"Application": {variant: VariantApplication,
+			expected: "https 1970-01-02T03:04:05.000000Z app/web-frontend/7b169c846f218ab5 239.135.34.15:17149 10.146.141.81:8080 0.001 0.199 0.000 200 200 2963 263134 \"GET https://static.example.com:443/ HTTP/1.1\" \"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/99.0.4844.84 Safari/537.36\" ECDHE-RSA-AES128-GCM-SHA256 TLSv1.2 arn:aws:elasticloadbalancing:ap-southeast-1:123456789012:targetgroup/web-frontend-targets/52fa82fbf86758bf \"Root=1-00017ca5-8a7b3edca492f2b8a67697c4\" \"static.example.com\" \"arn:aws:acm:ap-southeast-1:123456789012:certificate/cbe0255a-a5b7-444b-ac40-f84c892b9bff\" 23 1970-01-02T03:04:04.800000Z \"forward\" \"-\" \"-\" \"10.146.141.81:8080\" \"200\" \"-\" \"-\""},
+		"Network": {variant: VariantNetwork,
+			expected: "tls 2.0 1970-01-02T03:04:05 net/web-frontend/7b169c846f218ab5 52fa82fbf86758bf 46.201.242.24:40104 213.42.212.46:443 29828 61 2078 587965 - arn:aws:acm:ap-southeast-1:123456789012:certificate/f17a4c72-15a3-4539-ab1e-5849c6077dbb - ECDHE-RSA-AES128-GCM-SHA256 tlsv12 - static.example.com http/1.1 http/1.1 http/1.1 1970-01-02T03:03:35"},
+	}
+	testTime, err := time.Parse(time.RFC3339, "1970-01-02T03:04:05Z")
+	assert.Nil(t, err)
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "variant": tc.variant})
+		assert.Nil(t, err, name)
+		e, err := New(c, rand.New(rand.NewSource(1)), clock.Fixed(testTime))
+		assert.Nil(t, err, name)
+		got, err := e.Next()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
+
+func TestFields(t *testing.T) {
+	tests := map[string]struct {
+		variant string
+		fields  int
+	}{
+		"Classic":     {variant: VariantClassic, fields: 15},
+		"Application": {variant: VariantApplication, fields: 29},
+		"Network":     {variant: VariantNetwork, fields: 22},
+	}
+	// field is a quoted string or a run of non spaces.
+	field := regexp.MustCompile(`"[^"]*"|\S+`)
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "variant": tc.variant})
+		assert.Nil(t, err, name)
+		e, err := New(c, rand.New(rand.NewSource(1)), clock.Real)
+		assert.Nil(t, err, name)
+		for i := 0; i < 1000; i++ {
+			got, err := e.Next()
+			assert.Nil(t, err, name)
+			assert.Len(t, field.FindAll(got, -1), tc.fields, "%s: %s", name, got)
+			assert.NotContains(t, string(got), "<no value>", name)
+		}
+	}
+}
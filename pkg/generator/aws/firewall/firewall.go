@@ -20,6 +20,7 @@ import (
 	"time"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/leehinman/spigot/pkg/generator"
 	"github.com/leehinman/spigot/pkg/random"
 )
@@ -122,14 +123,20 @@ type Generator struct {
 	Data Firewall
 
 	eventType string
+	rnd       *rand.Rand
+	clock     clock.Clock
 }
 
 func init() {
 	_ = generator.Register(Name, New)
+	generator.RegisterInfo(Name, generator.Info{
+		Description: "AWS Network Firewall netflow and alert logs as JSON.",
+		Config:      defaultConfig(),
+	})
 }
 
 // New is the factory for AWS Firewall objects.
-func New(cfg *ucfg.Config) (generator.Generator, error) {
+func New(cfg *ucfg.Config, r *rand.Rand, clk clock.Clock) (generator.Generator, error) {
 	c := defaultConfig()
 	if err := cfg.Unpack(&c); err != nil {
 		return nil, err
@@ -137,6 +144,8 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 
 	g := Generator{
 		eventType: c.EventType,
+		rnd:       r,
+		clock:     clk,
 	}
 
 	return &g, nil
@@ -157,24 +166,24 @@ func (g *Generator) Next() ([]byte, error) {
 }
 
 func (g *Generator) randomize() {
-	now := time.Now()
+	now := g.clock.Now()
 	g.Data = Firewall{
-		FirewallName:     fmt.Sprintf("Firewall-%d", rand.Intn(100)),
-		AvailabilityZone: random.AWSAvailabilityZone(),
+		FirewallName:     fmt.Sprintf("Firewall-%d", g.rnd.Intn(100)),
+		AvailabilityZone: random.AWSAvailabilityZone(g.rnd),
 		EventTimestamp:   strconv.Itoa(int(now.Unix())),
 		Event: EventData{
 			Timestamp: now.Format(timestampFmt),
-			FlowID:    rand.Int(),
-			SrcIP:     random.IPv4(),
-			SrcPort:   random.Port(),
-			DstIP:     random.IPv4(),
-			DstPort:   random.Port(),
-			Proto:     protocols[rand.Intn(len(protocols))],
+			FlowID:    g.rnd.Int(),
+			SrcIP:     random.IPv4(g.rnd),
+			SrcPort:   random.Port(g.rnd),
+			DstIP:     random.IPv4(g.rnd),
+			DstPort:   random.Port(g.rnd),
+			Proto:     protocols[g.rnd.Intn(len(protocols))],
 		},
 	}
 
 	if g.eventType == "" {
-		g.Data.Event.EventType = eventTypes[rand.Intn(len(eventTypes))]
+		g.Data.Event.EventType = eventTypes[g.rnd.Intn(len(eventTypes))]
 	} else {
 		g.Data.Event.EventType = g.eventType
 	}
@@ -188,14 +197,14 @@ func (g *Generator) randomize() {
 }
 
 func (g *Generator) randomizeAlert() {
-	signature := rand.Intn(1024)
+	signature := g.rnd.Intn(1024)
 	g.Data.Event.Alert = &AlertData{
-		Action:      alertActions[rand.Intn(len(alertActions))],
+		Action:      alertActions[g.rnd.Intn(len(alertActions))],
 		SignatureID: signature,
-		Rev:         rand.Intn(1024),
+		Rev:         g.rnd.Intn(1024),
 		Signature:   fmt.Sprintf("Signature-%d", signature),
-		Category:    fmt.Sprintf("Category-%d", rand.Intn(100)),
-		Severity:    rand.Intn(6),
+		Category:    fmt.Sprintf("Category-%d", g.rnd.Intn(100)),
+		Severity:    g.rnd.Intn(6),
 	}
 
 	if g.Data.Event.Proto == ProtocolTCP {
@@ -204,23 +213,23 @@ func (g *Generator) randomizeAlert() {
 }
 
 func (g *Generator) randomizeNetflow(now time.Time) {
-	ttl := rand.Intn(256)
-	start := now.Add(-time.Duration(rand.Intn(60)) * time.Minute)
+	ttl := g.rnd.Intn(256)
+	start := now.Add(-time.Duration(g.rnd.Intn(60)) * time.Minute)
 	g.Data.Event.Netflow = &NetflowData{
-		Pkts:   rand.Intn(100),
+		Pkts:   g.rnd.Intn(100),
 		Start:  start.Format(timestampFmt),
 		End:    now.Format(timestampFmt),
 		Age:    int(now.Sub(start).Seconds()),
 		MinTTL: ttl,
 		MaxTTL: ttl,
 	}
-	g.Data.Event.Netflow.Bytes = g.Data.Event.Netflow.Pkts*rand.Intn(1024) + 1
+	g.Data.Event.Netflow.Bytes = g.Data.Event.Netflow.Pkts*g.rnd.Intn(1024) + 1
 }
 
 func (g *Generator) randomizeTCP() {
-	g.Data.Event.AppProto = tcpAppProtos[rand.Intn(len(tcpAppProtos))]
+	g.Data.Event.AppProto = tcpAppProtos[g.rnd.Intn(len(tcpAppProtos))]
 
-	flags := rand.Intn(64)
+	flags := g.rnd.Intn(64)
 	g.Data.Event.TCP = &TCPData{
 		TCPFlags: fmt.Sprintf("%02d", flags),
 		Fin:      flags&(1<<0) != 0,
@@ -238,11 +247,11 @@ func (g *Generator) randomizeTCP() {
 
 func (g *Generator) randomizeHTTP() {
 	g.Data.Event.HTTP = &HTTPData{
-		Hostname:      fmt.Sprintf("HTTPHost-%d", rand.Intn(100)),
-		URL:           fmt.Sprintf("/random-%d.html", rand.Intn(100)),
-		HTTPUserAgent: random.UserAgent(),
-		HTTPMethod:    random.HTTPMethod(),
-		Protocol:      random.HTTPVersion(),
-		Length:        rand.Intn(1024),
+		Hostname:      fmt.Sprintf("HTTPHost-%d", g.rnd.Intn(100)),
+		URL:           fmt.Sprintf("/random-%d.html", g.rnd.Intn(100)),
+		HTTPUserAgent: random.UserAgent(g.rnd),
+		HTTPMethod:    random.HTTPMethod(g.rnd),
+		Protocol:      random.HTTPVersion(g.rnd),
+		Length:        g.rnd.Intn(1024),
 	}
 }
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -72,9 +73,8 @@ func TestNext(t *testing.T) {
 	for name, tc := range tests {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
-			rand.Seed(1)
 			var got Firewall
-			g, err := New(ucfg.MustNewFrom(tc.config))
+			g, err := New(ucfg.MustNewFrom(tc.config), rand.New(rand.NewSource(1)), clock.Real)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -101,8 +101,7 @@ func TestNext(t *testing.T) {
 func BenchmarkGenerator_Next(b *testing.B) {
 	b.ReportAllocs()
 
-	rand.Seed(1)
-	g, err := New(ucfg.New())
+	g, err := New(ucfg.New(), rand.New(rand.NewSource(1)), clock.Real)
 	if err != nil {
 		b.Fatal(err)
 	}
@@ -1,9 +1,11 @@
 package vpcflow
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -32,7 +34,7 @@ func TestConfigs(t *testing.T) {
 	for name, tc := range tests {
 		c, err := ucfg.NewFrom(tc.c)
 		assert.Nil(t, err, name)
-		_, err = New(c)
+		_, err = New(c, rand.New(rand.NewSource(1)), clock.Real)
 		if tc.hasError {
 			assert.NotNil(t, err, name)
 			assert.Equal(t, err.Error(), tc.errorString, name)
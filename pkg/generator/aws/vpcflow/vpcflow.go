@@ -11,9 +11,9 @@ import (
 	"math/rand"
 	"net"
 	"text/template"
-	"time"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/leehinman/spigot/pkg/generator"
 	"github.com/leehinman/spigot/pkg/random"
 )
@@ -44,20 +44,26 @@ type Vpcflow struct {
 	Action    string
 	LogStatus string
 	template  *template.Template
+	rnd       *rand.Rand
+	clock     clock.Clock
 }
 
 func init() {
 	generator.Register(Name, New)
+	generator.RegisterInfo(Name, generator.Info{
+		Description: "Version 2 AWS VPC flow logs.",
+		Config:      defaultConfig(),
+	})
 }
 
 // New is the Factory for Vpcflow objects.
-func New(cfg *ucfg.Config) (generator.Generator, error) {
+func New(cfg *ucfg.Config, r *rand.Rand, clk clock.Clock) (generator.Generator, error) {
 	c := defaultConfig()
 	if err := cfg.Unpack(&c); err != nil {
 		return nil, err
 	}
 
-	v := &Vpcflow{}
+	v := &Vpcflow{rnd: r, clock: clk}
 
 	t, err := template.New("vpcflow").Funcs(generator.FunctionMap).Parse(vpcFlowTemplate)
 	if err != nil {
@@ -90,19 +96,19 @@ func (v *Vpcflow) Next() ([]byte, error) {
 }
 
 func (v *Vpcflow) randomize() {
-	v.SrcAddr = random.IPv4()
-	v.DstAddr = random.IPv4()
-	v.SrcPort = random.Port()
-	v.DstPort = random.Port()
-	v.Protocol = rand.Intn(256)
-	v.Packets = rand.Intn(1048576)
+	v.SrcAddr = random.IPv4(v.rnd)
+	v.DstAddr = random.IPv4(v.rnd)
+	v.SrcPort = random.Port(v.rnd)
+	v.DstPort = random.Port(v.rnd)
+	v.Protocol = v.rnd.Intn(256)
+	v.Packets = v.rnd.Intn(1048576)
 	v.Bytes = v.Packets * 1500
-	v.End = time.Now().Unix()
-	v.Start = v.End - int64(rand.Intn(60))
-	v.Action = actions[rand.Intn(2)]
+	v.End = v.clock.Now().Unix()
+	v.Start = v.End - int64(v.rnd.Intn(60))
+	v.Action = actions[v.rnd.Intn(2)]
 	if v.Packets == 0 {
 		v.LogStatus = statuses[2]
 	} else {
-		v.LogStatus = statuses[rand.Intn(2)]
+		v.LogStatus = statuses[v.rnd.Intn(2)]
 	}
 }
@@ -4,7 +4,9 @@ import (
 	"math/rand"
 	"testing"
 	"text/template"
+	"time"
 
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/leehinman/spigot/pkg/generator"
 	"github.com/stretchr/testify/assert"
 )
@@ -21,13 +23,11 @@ func TestNext(t *testing.T) {
 	}
 
 	for name, tc := range tests {
-		rand.Seed(1)
-		v := &Vpcflow{}
+		v := &Vpcflow{rnd: rand.New(rand.NewSource(1)), clock: clock.Fixed(time.Unix(42, 0))}
 		tmpl, err := template.New(name).Funcs(generator.FunctionMap).Parse(tc.template)
 		assert.Nil(t, err, name)
 		v.template = tmpl
 		v.randomize()
-		v.End = 42
 		v.Start = 2
 		got, err := v.Next()
 		assert.Nil(t, err, name)
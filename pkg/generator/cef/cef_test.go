@@ -37,7 +37,7 @@ func TestNext(t *testing.T) {
 	}}
 	c.config.Validate() // Populate the remaining fields with the defaults.
 	for _, test := range tests {
-		rand.Seed(test.seed)
+		c.rnd = rand.New(rand.NewSource(test.seed))
 		c.Max = 10
 		c.randomize()
 		got, err := c.Next()
@@ -1,22 +1,33 @@
-// Package asa implements the generator for Cisco ASA logs.
+// Package asa implements the generator for Cisco ASA logs, including
+// the 430xxx connection, intrusion and file events of Firepower Threat
+// Defense.
 //
-// Configuration file supports including timestamps in log messages
+// Configuration file supports including timestamps in log messages,
+// choosing the message IDs to generate and weighting them.  By
+// default every supported message ID is generated equally often.
+// "weights", if set, has one weight for each of "message_ids".
+// Messages that tear down a connection or session report one that
+// was built earlier, with the same connection ID, while its build
+// message is also being generated.
 //
-//   generator:
-//     type: cisco:asa
-//     include_timestamp: true
+//	generator:
+//	  type: cisco:asa
+//	  include_timestamp: true
+//	  message_ids: [302013, 302014, 106023]
+//	  weights: [5, 5, 1]
 package asa
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
 	"math/rand"
 	"net"
-	"strconv"
 	"text/template"
 	"time"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/leehinman/spigot/pkg/generator"
 	"github.com/leehinman/spigot/pkg/random"
 )
@@ -24,16 +35,120 @@ import (
 // Name is the name of the generator in the configuration file and registry
 const Name = "cisco:asa"
 
+// maxOpen is the most connections of each kind that are kept open
+// waiting for their teardown.  The oldest is dropped when there are
+// more.
+const maxOpen = 1024
+
+const header = "{{if .IncludeTimestamp}}{{.Timestamp.Format \"Jan 02 2006 03:04:05\"}}: {{end}}"
+
+// ftdConnection starts the FTD connection, intrusion and file events.
+const ftdConnection = "DeviceUUID: {{.DeviceUuid}}, InstanceID: 1, FirstPacketSecond: {{.Start.UTC.Format \"2006-01-02T15:04:05Z\"}}, ConnectionID: {{.ConnectionId}}, "
+
 var (
-	asa106023    = "{{if .IncludeTimestamp}}{{.Timestamp.Format \"Jan 02 2006 03:04:05\"}}: {{end}}%ASA-4-106023: Deny {{.Protocol | ToLower}} src {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} dst {{.DstInt}}:{{.DstAddr}}/{{.DstPort}} type {{.Type}} code {{.Code}} by {{.AccessGroup | ToLower}} \"{{.AclId}}\" [0x8ed66b60, 0xf8852875]"
-	asa302013    = "{{if .IncludeTimestamp}}{{.Timestamp.Format \"Jan 02 2006 03:04:05\"}}: {{end}}%ASA-6-302013: Built {{.Direction}} TCP connection {{.ConnectionId}} for {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} ({{.Map1Addr}}/{{.Map1Port}}) to {{.DstInt}}:{{.DstAddr}}/{{.DstPort}} ({{.Map2Addr}}/{{.Map2Port}})"
-	asa302014    = "{{if .IncludeTimestamp}}{{.Timestamp.Format \"Jan 02 2006 03:04:05\"}}: {{end}}%ASA-6-302014: Teardown TCP connection {{.ConnectionId}} for {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} to {{.DstInt}}:{{.DstAddr}}/{{.DstPort}} duration {{.Duration}} bytes {{.Bytes}} {{.Reason}}"
-	asa305011    = "{{if .IncludeTimestamp}}{{.Timestamp.Format \"Jan 02 2006 03:04:05\"}}: {{end}}%ASA-6-305011: Built {{.TranslationType}} {{.Protocol}} translation from {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} to {{.DstInt}}:{{.DstAddr}}/{{.DstPort}}"
-	msgTemplates = [...]string{
-		asa106023,
-		asa302013,
-		asa302014,
-		asa305011,
+	asa104001 = header + "%ASA-1-104001: ({{.FailoverUnit}}) Switching to ACTIVE - {{.FailoverReason}}."
+	asa104002 = header + "%ASA-1-104002: ({{.FailoverUnit}}) Switching to STANDBY - {{.FailoverReason}}."
+	asa105005 = header + "%ASA-1-105005: ({{.FailoverUnit}}) Lost Failover communications with mate on interface {{.SrcInt}}."
+	asa106001 = header + "%ASA-2-106001: Inbound TCP connection denied from {{.SrcAddr}}/{{.SrcPort}} to {{.DstAddr}}/{{.DstPort}} flags {{.TcpFlags}} on interface {{.SrcInt}}"
+	asa106015 = header + "%ASA-6-106015: Deny TCP (no connection) from {{.SrcAddr}}/{{.SrcPort}} to {{.DstAddr}}/{{.DstPort}} flags {{.TcpFlags}} on interface {{.SrcInt}}"
+	asa106023 = header + "%ASA-4-106023: Deny {{.Protocol | ToLower}} src {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} dst {{.DstInt}}:{{.DstAddr}}/{{.DstPort}} type {{.Type}} code {{.Code}} by {{.AccessGroup | ToLower}} \"{{.AclId}}\" [0x8ed66b60, 0xf8852875]"
+	asa106100 = header + "%ASA-6-106100: access-list {{.AclId}} {{.AclAction}} {{.Protocol | ToLower}} {{.SrcInt}}/{{.SrcAddr}}({{.SrcPort}}) -> {{.DstInt}}/{{.DstAddr}}({{.DstPort}}) hit-cnt {{.HitCount}} {{if eq .HitCount 1}}first hit{{else}}300-second interval{{end}} [0x{{printf \"%08x\" .HashCode}}, 0x0]"
+	asa110002 = header + "%ASA-6-110002: Failed to locate egress interface for {{.Protocol}} from {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} to {{.DstAddr}}/{{.DstPort}}"
+	asa113004 = header + "%ASA-6-113004: AAA user authentication Successful : server = {{.AaaServer}} : user = {{.SrcUser}}"
+	asa113005 = header + "%ASA-6-113005: AAA user authentication Rejected : reason = {{.AaaReason}} : server = {{.AaaServer}} : user = {{.SrcUser}} : user IP = {{.SrcAddr}}"
+	asa113019 = header + "%ASA-4-113019: Group = {{.Group}}, Username = {{.SrcUser}}, IP = {{.SrcAddr}}, Session disconnected. Session Type: {{.SessionType}}, Duration: {{.VpnDuration}}, Bytes xmt: {{.Bytes}}, Bytes rcv: {{.BytesReceived}}, Reason: {{.SessionReason}}"
+	asa302013 = header + "%ASA-6-302013: Built {{.Direction}} TCP connection {{.ConnectionId}} for {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} ({{.Map1Addr}}/{{.Map1Port}}) to {{.DstInt}}:{{.DstAddr}}/{{.DstPort}} ({{.Map2Addr}}/{{.Map2Port}})"
+	asa302014 = header + "%ASA-6-302014: Teardown TCP connection {{.ConnectionId}} for {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} to {{.DstInt}}:{{.DstAddr}}/{{.DstPort}} duration {{.Duration}} bytes {{.Bytes}} {{.Reason}}"
+	asa302015 = header + "%ASA-6-302015: Built {{.Direction}} UDP connection {{.ConnectionId}} for {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} ({{.Map1Addr}}/{{.Map1Port}}) to {{.DstInt}}:{{.DstAddr}}/{{.DstPort}} ({{.Map2Addr}}/{{.Map2Port}})"
+	asa302016 = header + "%ASA-6-302016: Teardown UDP connection {{.ConnectionId}} for {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} to {{.DstInt}}:{{.DstAddr}}/{{.DstPort}} duration {{.Duration}} bytes {{.Bytes}}"
+	asa302017 = header + "%ASA-6-302017: Built {{.Direction}} GRE connection {{.ConnectionId}} from {{.SrcInt}}:{{.SrcAddr}} ({{.Map1Addr}}) to {{.DstInt}}:{{.DstAddr}}/0 ({{.Map2Addr}}/0)"
+	asa302018 = header + "%ASA-6-302018: Teardown GRE connection {{.ConnectionId}} from {{.SrcInt}}:{{.SrcAddr}} ({{.Map1Addr}}) to {{.DstInt}}:{{.DstAddr}}/0 ({{.Map2Addr}}/0) duration {{.Duration}} bytes {{.Bytes}}"
+	asa302020 = header + "%ASA-6-302020: Built {{.Direction}} ICMP connection for faddr {{.DstAddr}}/{{.SrcPort}} gaddr {{.Map1Addr}}/0 laddr {{.SrcAddr}}/0 type {{.IcmpType}} code {{.IcmpCode}}"
+	asa302021 = header + "%ASA-6-302021: Teardown ICMP connection for faddr {{.DstAddr}}/{{.SrcPort}} gaddr {{.Map1Addr}}/0 laddr {{.SrcAddr}}/0 type {{.IcmpType}} code {{.IcmpCode}}"
+	asa305011 = header + "%ASA-6-305011: Built {{.TranslationType}} {{.Protocol}} translation from {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} to {{.DstInt}}:{{.DstAddr}}/{{.DstPort}}"
+	asa305012 = header + "%ASA-6-305012: Teardown {{.TranslationType}} {{.Protocol}} translation from {{.SrcInt}}:{{.SrcAddr}}/{{.SrcPort}} to {{.DstInt}}:{{.DstAddr}}/{{.DstPort}} duration {{.Duration}}"
+	asa313001 = header + "%ASA-3-313001: Denied ICMP type={{.IcmpType}}, code={{.IcmpCode}} from {{.SrcAddr}} on interface {{.SrcInt}}"
+	asa400010 = header + "%ASA-4-400010: IPS:2000 ICMP Echo Reply from {{.SrcAddr}} to {{.DstAddr}} on interface {{.SrcInt}}"
+	asa400011 = header + "%ASA-4-400011: IPS:2001 ICMP Host Unreachable from {{.SrcAddr}} to {{.DstAddr}} on interface {{.SrcInt}}"
+	asa400014 = header + "%ASA-4-400014: IPS:2004 ICMP Echo Request from {{.SrcAddr}} to {{.DstAddr}} on interface {{.SrcInt}}"
+	asa400023 = header + "%ASA-4-400023: IPS:2150 ICMP fragments from {{.SrcAddr}} to {{.DstAddr}} on interface {{.SrcInt}}"
+	asa400025 = header + "%ASA-4-400025: IPS:2154 ICMP ping of death from {{.SrcAddr}} to {{.DstAddr}} on interface {{.SrcInt}}"
+	asa400026 = header + "%ASA-4-400026: IPS:3040 TCP NULL flags from {{.SrcAddr}} to {{.DstAddr}} on interface {{.SrcInt}}"
+	asa400027 = header + "%ASA-4-400027: IPS:3041 TCP SYN+FIN flags from {{.SrcAddr}} to {{.DstAddr}} on interface {{.SrcInt}}"
+	asa400032 = header + "%ASA-4-400032: IPS:4051 UDP Snork attack from {{.SrcAddr}} to {{.DstAddr}} on interface {{.SrcInt}}"
+	asa605004 = header + "%ASA-6-605004: Login denied from {{.SrcAddr}}/{{.SrcPort}} to {{.SrcInt}}:{{.DstAddr}}/{{.Service}} for user \"{{.SrcUser}}\""
+	asa605005 = header + "%ASA-6-605005: Login permitted from {{.SrcAddr}}/{{.SrcPort}} to {{.SrcInt}}:{{.DstAddr}}/{{.Service}} for user \"{{.SrcUser}}\""
+	asa710003 = header + "%ASA-3-710003: {{.Protocol}} access denied by ACL from {{.SrcAddr}}/{{.SrcPort}} to {{.SrcInt}}:{{.DstAddr}}/{{.DstPort}}"
+	asa722022 = header + "%ASA-6-722022: Group <{{.Group}}> User <{{.SrcUser}}> IP <{{.SrcAddr}}> {{.Protocol}} SVC connection established without compression"
+	asa722023 = header + "%ASA-6-722023: Group <{{.Group}}> User <{{.SrcUser}}> IP <{{.SrcAddr}}> {{.Protocol}} SVC connection terminated without compression"
+	asa722037 = header + "%ASA-4-722037: Group <{{.Group}}> User <{{.SrcUser}}> IP <{{.SrcAddr}}> SVC closing connection: {{.SessionReason}}."
+	asa722051 = header + "%ASA-4-722051: Group <{{.Group}}> User <{{.SrcUser}}> IP <{{.SrcAddr}}> IPv4 Address <{{.Map1Addr}}> IPv6 address <::> assigned to session"
+	asa722055 = header + "%ASA-6-722055: Group <{{.Group}}> User <{{.SrcUser}}> IP <{{.SrcAddr}}> Client Type: {{.ClientType}}"
+	asa733100 = header + "%ASA-4-733100: [ {{.DropObject}}] drop rate-{{.RateId}} exceeded. Current burst rate is {{.BurstRate}} per second, max configured rate is {{.MaxBurstRate}}; Current average rate is {{.AvgRate}} per second, max configured rate is {{.MaxAvgRate}}; Cumulative total count is {{.TotalCount}}"
+	ftd430001 = header + "%FTD-1-430001: " + ftdConnection + "Protocol: {{.Protocol | ToLower}}, SrcIP: {{.SrcAddr}}, DstIP: {{.DstAddr}}, SrcPort: {{.SrcPort}}, DstPort: {{.DstPort}}, Priority: {{.Intrusion.Priority}}, GID: 1, SID: {{.Intrusion.Sid}}, Revision: {{.Intrusion.Revision}}, Message: {{.Intrusion.Message}}, Classification: {{.Intrusion.Classification}}, IngressInterface: {{.SrcInt}}, EgressInterface: {{.DstInt}}, IngressZone: {{.SrcZone}}, EgressZone: {{.DstZone}}, ApplicationProtocol: {{.AppProtocol}}, IntrusionPolicy: {{.IntrusionPolicy}}, ACPolicy: {{.AcPolicy}}, AccessControlRuleName: {{.AclId}}, InlineResult: {{.InlineResult}}"
+	ftd430002 = header + "%FTD-1-430002: " + ftdConnection + "AccessControlRuleAction: {{.RuleAction}}, SrcIP: {{.SrcAddr}}, DstIP: {{.DstAddr}}, SrcPort: {{.SrcPort}}, DstPort: {{.DstPort}}, Protocol: {{.Protocol | ToLower}}, IngressInterface: {{.SrcInt}}, EgressInterface: {{.DstInt}}, IngressZone: {{.SrcZone}}, EgressZone: {{.DstZone}}, ACPolicy: {{.AcPolicy}}, AccessControlRuleName: {{.AclId}}, Prefilter Policy: Default Prefilter Policy, User: {{.SrcUser}}, InitiatorPackets: 1, ResponderPackets: 0, InitiatorBytes: 66, ResponderBytes: 0, NAPPolicy: Balanced Security and Connectivity"
+	ftd430003 = header + "%FTD-1-430003: " + ftdConnection + "AccessControlRuleAction: {{.RuleAction}}, SrcIP: {{.SrcAddr}}, DstIP: {{.DstAddr}}, SrcPort: {{.SrcPort}}, DstPort: {{.DstPort}}, Protocol: {{.Protocol | ToLower}}, IngressInterface: {{.SrcInt}}, EgressInterface: {{.DstInt}}, IngressZone: {{.SrcZone}}, EgressZone: {{.DstZone}}, ACPolicy: {{.AcPolicy}}, AccessControlRuleName: {{.AclId}}, Prefilter Policy: Default Prefilter Policy, User: {{.SrcUser}}, ApplicationProtocol: {{.AppProtocol}}, ConnectionDuration: {{.Elapsed}}, InitiatorPackets: {{.Packets}}, ResponderPackets: {{.PacketsReceived}}, InitiatorBytes: {{.Bytes}}, ResponderBytes: {{.BytesReceived}}, NAPPolicy: Balanced Security and Connectivity"
+	ftd430004 = header + "%FTD-1-430004: " + ftdConnection + "SrcIP: {{.SrcAddr}}, DstIP: {{.DstAddr}}, SrcPort: {{.SrcPort}}, DstPort: {{.DstPort}}, Protocol: {{.Protocol | ToLower}}, FileDirection: Download, FileAction: {{.FileAction}}, FileSHA256: {{.FileSha256}}, SHA_Disposition: Unknown, SperoDisposition: Spero detection not performed on file, FileName: {{.File.Name}}, FileType: {{.File.Type}}, FileSize: {{.FileSize}}, ApplicationProtocol: {{.AppProtocol}}, User: {{.SrcUser}}, FileSandboxStatus: File not sent for analysis, IngressZone: {{.SrcZone}}, EgressZone: {{.DstZone}}, IngressInterface: {{.SrcInt}}, EgressInterface: {{.DstInt}}, ACPolicy: {{.AcPolicy}}"
+	ftd430005 = header + "%FTD-1-430005: " + ftdConnection + "SrcIP: {{.SrcAddr}}, DstIP: {{.DstAddr}}, SrcPort: {{.SrcPort}}, DstPort: {{.DstPort}}, Protocol: {{.Protocol | ToLower}}, FileDirection: Download, FileAction: Malware Block, FileSHA256: {{.FileSha256}}, SHA_Disposition: Malware, SperoDisposition: Spero detection not performed on file, ThreatName: {{.File.ThreatName}}, FileName: {{.File.Name}}, FileType: {{.File.Type}}, FileSize: {{.FileSize}}, ApplicationProtocol: {{.AppProtocol}}, User: {{.SrcUser}}, FileSandboxStatus: File not sent for analysis, IngressZone: {{.SrcZone}}, EgressZone: {{.DstZone}}, IngressInterface: {{.SrcInt}}, EgressInterface: {{.DstInt}}, ACPolicy: {{.AcPolicy}}"
+
+	// messages are the supported message IDs and their templates.
+	messages = [...]message{
+		{"104001", asa104001},
+		{"104002", asa104002},
+		{"105005", asa105005},
+		{"106001", asa106001},
+		{"106015", asa106015},
+		{"106023", asa106023},
+		{"106100", asa106100},
+		{"110002", asa110002},
+		{"113004", asa113004},
+		{"113005", asa113005},
+		{"113019", asa113019},
+		{"302013", asa302013},
+		{"302014", asa302014},
+		{"302015", asa302015},
+		{"302016", asa302016},
+		{"302017", asa302017},
+		{"302018", asa302018},
+		{"302020", asa302020},
+		{"302021", asa302021},
+		{"305011", asa305011},
+		{"305012", asa305012},
+		{"313001", asa313001},
+		{"400010", asa400010},
+		{"400011", asa400011},
+		{"400014", asa400014},
+		{"400023", asa400023},
+		{"400025", asa400025},
+		{"400026", asa400026},
+		{"400027", asa400027},
+		{"400032", asa400032},
+		{"430001", ftd430001},
+		{"430002", ftd430002},
+		{"430003", ftd430003},
+		{"430004", ftd430004},
+		{"430005", ftd430005},
+		{"605004", asa605004},
+		{"605005", asa605005},
+		{"710003", asa710003},
+		{"722022", asa722022},
+		{"722023", asa722023},
+		{"722037", asa722037},
+		{"722051", asa722051},
+		{"722055", asa722055},
+		{"733100", asa733100},
+	}
+	// teardowns maps the message IDs that end a connection or session
+	// to the message ID that builds it.
+	teardowns = map[string]string{
+		"113019": "722022",
+		"302014": "302013",
+		"302016": "302015",
+		"302018": "302017",
+		"302021": "302020",
+		"305012": "305011",
+		"430003": "430002",
+		"722023": "722022",
 	}
 	directions       = [...]string{"inbound", "outbound"}
 	protocols        = [...]string{"TCP", "UDP"}
@@ -77,43 +192,154 @@ var (
 		"Unknown",
 		"Xlate Clear",
 	}
+	users           = [...]string{"user01", "user02", "user03", "user04", "user05", "user06", "user07"}
+	groups          = [...]string{"GroupPolicy_Employees", "GroupPolicy_Contractors", "DfltGrpPolicy"}
+	tcpFlags        = [...]string{"SYN", "ACK", "FIN ACK", "RST", "RST ACK", "PSH ACK"}
+	aclActions      = [...]string{"permitted", "denied", "est-allowed"}
+	aaaReasons      = [...]string{"AAA failure", "Invalid password", "Unspecified"}
+	sessionTypes    = [...]string{"AnyConnect-Parent", "SSL", "DTLS", "IKEv2"}
+	sessionReasons  = [...]string{"User Requested", "Idle Timeout", "Max time exceeded", "Lost Service", "DPD failure"}
+	failoverUnits   = [...]string{"Primary", "Secondary"}
+	failoverReasons = [...]string{"Other unit wants me Active", "Other unit wants me Standby", "No Active unit found", "Set by the config command", "Interface check"}
+	icmpTypes       = [...]int{0, 3, 8, 11}
+	services        = [...]string{"ssh", "https", "telnet"}
+	clientTypes     = [...]string{
+		"Cisco AnyConnect VPN Agent for Windows 4.10.05095",
+		"Cisco AnyConnect VPN Agent for Mac OS X 4.10.05095",
+		"Cisco Secure Client for Windows 5.0.02075",
+	}
+	dropObjects   = [...]string{"Scanning", "Syn Attack", "Firewall", "Interface", "Dos"}
+	ruleActions   = [...]string{"Allow", "Trust", "Block"}
+	appProtocols  = [...]string{"HTTP", "HTTPS", "DNS", "SMTP", "SSH"}
+	inlineResults = [...]string{"blocked", "would have blocked", ""}
+	fileActions   = [...]string{"Detect", "Block", "Allow"}
+	intrusions    = [...]Intrusion{
+		{Sid: 58722, Revision: 3, Priority: 1, Message: "SERVER-OTHER Apache Log4j logging remote code execution attempt", Classification: "Attempted User Privilege Gain"},
+		{Sid: 41978, Revision: 5, Priority: 1, Message: "OS-WINDOWS Microsoft Windows SMB remote code execution attempt", Classification: "Attempted Administrator Privilege Gain"},
+		{Sid: 1201, Revision: 11, Priority: 2, Message: "INDICATOR-COMPROMISE 403 Forbidden", Classification: "Attempted Information Leak"},
+		{Sid: 29456, Revision: 3, Priority: 3, Message: "PROTOCOL-ICMP Unusual PING detected", Classification: "Information Leak"},
+	}
+	files = [...]File{
+		{Name: "invoice.exe", Type: "MSEXE", ThreatName: "Win.Ransomware.Generic::sbmt"},
+		{Name: "report.pdf", Type: "PDF", ThreatName: "Pdf.Exploit.CVE_2018_4993"},
+		{Name: "update.zip", Type: "ZIP", ThreatName: "Win.Trojan.Agent"},
+		{Name: "macro.docm", Type: "NEW_OFFICE", ThreatName: "Doc.Dropper.Emotet"},
+	}
 )
 
+type message struct {
+	id       string
+	template string
+}
+
+// Connection holds the fields that a teardown message shares with the
+// build message of the same connection or session.
+type Connection struct {
+	AclId           string
+	ConnectionId    int
+	Direction       string
+	DstAddr         net.IP
+	DstInt          string
+	DstPort         int
+	Group           string
+	IcmpCode        int
+	IcmpType        int
+	Map1Addr        net.IP
+	Map1Port        int
+	Map2Addr        net.IP
+	Map2Port        int
+	Protocol        string
+	RuleAction      string
+	SrcAddr         net.IP
+	SrcInt          string
+	SrcPort         int
+	SrcUser         string
+	Start           time.Time
+	TranslationType string
+}
+
+// Intrusion is a Snort rule reported by FTD intrusion events.
+type Intrusion struct {
+	Sid            int
+	Revision       int
+	Priority       int
+	Message        string
+	Classification string
+}
+
+// File is a file reported by FTD file and malware events.
+type File struct {
+	Name       string
+	Type       string
+	ThreatName string
+}
+
 type Asa struct {
+	Connection
+	AaaReason        string
+	AaaServer        net.IP
 	AccessGroup      string
-	AclId            string
+	AclAction        string
+	AcPolicy         string
+	AppProtocol      string
+	AvgRate          int
+	BurstRate        int
 	Bytes            int
+	BytesReceived    int
+	ClientType       string
 	Code             int
-	ConnectionId     int
-	Direction        string
-	DstAddr          net.IP
-	DstInt           string
-	DstPort          int
+	DeviceUuid       string
+	DropObject       string
 	DstUser          string
+	DstZone          string
 	Duration         string
+	Elapsed          int
+	FailoverReason   string
+	FailoverUnit     string
+	File             File
+	FileAction       string
+	FileSha256       string
+	FileSize         int
+	HashCode         uint32
+	HitCount         int
 	IncludeTimestamp bool
-	Map1Addr         net.IP
-	Map1Port         int
-	Map2Addr         net.IP
-	Map2Port         int
-	Protocol         string
+	InlineResult     string
+	Intrusion        Intrusion
+	IntrusionPolicy  string
+	MaxAvgRate       int
+	MaxBurstRate     int
+	Packets          int
+	PacketsReceived  int
+	RateId           int
 	Reason           string
-	SrcAddr          net.IP
-	SrcInt           string
-	SrcPort          int
-	SrcUser          string
+	Service          string
+	SessionReason    string
+	SessionType      string
+	SrcZone          string
 	Timestamp        time.Time
-	TranslationType  string
+	TcpFlags         string
+	TotalCount       int
 	Type             int
+	VpnDuration      string
+	ids              []string
 	templates        []*template.Template
+	weighted         *random.Weighted
+	open             map[string][]Connection
+	nextConnectionId int
+	rnd              *rand.Rand
+	clock            clock.Clock
 }
 
 func init() {
 	generator.Register(Name, New)
+	generator.RegisterInfo(Name, generator.Info{
+		Description: "Cisco ASA and Firepower Threat Defense firewall syslog messages.",
+		Config:      defaultConfig(),
+	})
 }
 
 // New is Factory for the asa generator
-func New(cfg *ucfg.Config) (generator.Generator, error) {
+func New(cfg *ucfg.Config, r *rand.Rand, clk clock.Clock) (generator.Generator, error) {
 	c := defaultConfig()
 	if err := cfg.Unpack(&c); err != nil {
 		return nil, err
@@ -121,57 +347,185 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 
 	a := &Asa{
 		IncludeTimestamp: c.IncludeTimestamp,
+		open:             make(map[string][]Connection),
+		rnd:              r,
+		clock:            clk,
 	}
+	a.DeviceUuid = fmt.Sprintf("%08x-%04x-%04x-%04x-%012x", r.Uint32(), r.Intn(1<<16), r.Intn(1<<16), r.Intn(1<<16), r.Int63n(1<<48))
+	a.nextConnectionId = r.Intn(1 << 24)
 	a.randomize()
 
-	for i, v := range msgTemplates {
-		t, err := template.New(strconv.Itoa(i)).Funcs(generator.FunctionMap).Parse(v)
+	ids := c.MessageIds
+	if len(ids) == 0 {
+		ids = messageIds()
+	}
+	weights := c.Weights
+	if len(weights) == 0 {
+		weights = make([]float64, len(ids))
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	for i, id := range ids {
+		t, err := template.New(id).Funcs(generator.FunctionMap).Parse(messageTemplate(id))
 		if err != nil {
 			return nil, err
 		}
+		a.ids = append(a.ids, id)
 		a.templates = append(a.templates, t)
+		// Only keep connections open for teardowns that can be
+		// generated.
+		if build, ok := teardowns[id]; ok && weights[i] > 0 {
+			a.open[build] = nil
+		}
 	}
-
+	w, err := random.NewWeighted(weights)
+	if err != nil {
+		return nil, err
+	}
+	a.weighted = w
 	return a, nil
 }
 
+// messageIds returns the supported message IDs.
+func messageIds() []string {
+	ids := make([]string, len(messages))
+	for i, m := range messages {
+		ids[i] = m.id
+	}
+	return ids
+}
+
+// messageTemplate returns the template of message id, or "" if it is
+// not supported.
+func messageTemplate(id string) string {
+	for _, m := range messages {
+		if m.id == id {
+			return m.template
+		}
+	}
+	return ""
+}
+
 // Next produces the next asa log entry
 func (a *Asa) Next() ([]byte, error) {
 	var buf bytes.Buffer
 
-	err := a.templates[rand.Intn(len(a.templates))].Execute(&buf, a)
+	i := a.weighted.Pick(a.rnd)
+	a.track(a.ids[i])
+	err := a.templates[i].Execute(&buf, a)
 	if err != nil {
 		return nil, err
 	}
 
 	a.randomize()
-
 	return buf.Bytes(), err
 }
 
+// track opens a connection for build messages and, for teardown
+// messages, takes the fields of a connection opened earlier.
+// Teardowns of connections that were never built report the random
+// connection instead.
+func (a *Asa) track(id string) {
+	if build, ok := teardowns[id]; ok {
+		open := a.open[build]
+		if len(open) == 0 {
+			return
+		}
+		i := a.rnd.Intn(len(open))
+		a.Connection = open[i]
+		open[i] = open[len(open)-1]
+		a.open[build] = open[:len(open)-1]
+		a.setElapsed(a.Timestamp.Sub(a.Start))
+		return
+	}
+	open, ok := a.open[id]
+	if !ok {
+		return
+	}
+	a.Start = a.Timestamp
+	if len(open) == maxOpen {
+		open = open[1:]
+	}
+	a.open[id] = append(open, a.Connection)
+}
+
+// setElapsed sets the duration fields of teardown messages.
+func (a *Asa) setElapsed(d time.Duration) {
+	s := int(d / time.Second)
+	a.Elapsed = s
+	a.Duration = fmt.Sprintf("%01d:%02d:%02d", s/3600, s/60%60, s%60)
+	a.VpnDuration = fmt.Sprintf("%dh:%02dm:%02ds", s/3600, s/60%60, s%60)
+}
+
 func (a *Asa) randomize() {
+	a.Timestamp = a.clock.Now()
 	a.SrcInt = "SrcInt"
-	a.SrcUser = "SrcUser"
+	a.SrcZone = "SrcZone"
 	a.DstInt = "DstInt"
 	a.DstUser = "DstUser"
+	a.DstZone = "DstZone"
 	a.AccessGroup = "Access-Group"
 	a.AclId = "AclId"
-	a.Protocol = protocols[rand.Intn(len(protocols))]
-	a.TranslationType = translationTypes[rand.Intn(len(translationTypes))]
-	a.ConnectionId = rand.Intn(65536)
-	a.Duration = fmt.Sprintf("%01d:%02d:%02d", rand.Intn(4), rand.Intn(60), rand.Intn(60))
-	a.Bytes = rand.Intn(65536)
-	a.Reason = reasons[rand.Intn(len(reasons))]
-	a.SrcAddr = random.IPv4()
-	a.SrcPort = random.Port()
-	a.DstAddr = random.IPv4()
-	a.DstPort = random.Port()
-	a.Type = rand.Intn(64)
-	a.Code = rand.Intn(64)
-	a.Direction = directions[rand.Intn(len(directions))]
-	a.Map1Addr = random.IPv4()
-	a.Map1Port = random.Port()
-	a.Map2Addr = random.IPv4()
-	a.Map2Port = random.Port()
-	a.Timestamp = time.Now()
+	a.AcPolicy = "AcPolicy"
+	a.IntrusionPolicy = "IntrusionPolicy"
+	a.Protocol = protocols[a.rnd.Intn(len(protocols))]
+	a.TranslationType = translationTypes[a.rnd.Intn(len(translationTypes))]
+	a.ConnectionId = a.nextConnectionId
+	a.nextConnectionId++
+	elapsed := time.Duration(a.rnd.Intn(4*3600)) * time.Second
+	a.setElapsed(elapsed)
+	a.Start = a.Timestamp.Add(-elapsed)
+	a.Bytes = a.rnd.Intn(65536)
+	a.BytesReceived = a.rnd.Intn(1 << 20)
+	a.Packets = 1 + a.Bytes/1400
+	a.PacketsReceived = 1 + a.BytesReceived/1400
+	a.Reason = reasons[a.rnd.Intn(len(reasons))]
+	a.SrcAddr = random.IPv4(a.rnd)
+	a.SrcPort = random.Port(a.rnd)
+	a.DstAddr = random.IPv4(a.rnd)
+	a.DstPort = random.Port(a.rnd)
+	a.Type = a.rnd.Intn(64)
+	a.Code = a.rnd.Intn(64)
+	a.IcmpType = icmpTypes[a.rnd.Intn(len(icmpTypes))]
+	a.IcmpCode = 0
+	a.Direction = directions[a.rnd.Intn(len(directions))]
+	a.Map1Addr = random.IPv4(a.rnd)
+	a.Map1Port = random.Port(a.rnd)
+	a.Map2Addr = random.IPv4(a.rnd)
+	a.Map2Port = random.Port(a.rnd)
+	a.SrcUser = users[a.rnd.Intn(len(users))]
+	a.Group = groups[a.rnd.Intn(len(groups))]
+	a.TcpFlags = tcpFlags[a.rnd.Intn(len(tcpFlags))]
+	a.AclAction = aclActions[a.rnd.Intn(len(aclActions))]
+	a.HashCode = a.rnd.Uint32()
+	a.HitCount = 1
+	if a.rnd.Intn(2) == 0 {
+		a.HitCount += a.rnd.Intn(100)
+	}
+	a.AaaServer = random.IPv4(a.rnd)
+	a.AaaReason = aaaReasons[a.rnd.Intn(len(aaaReasons))]
+	a.SessionType = sessionTypes[a.rnd.Intn(len(sessionTypes))]
+	a.SessionReason = sessionReasons[a.rnd.Intn(len(sessionReasons))]
+	a.ClientType = clientTypes[a.rnd.Intn(len(clientTypes))]
+	a.FailoverUnit = failoverUnits[a.rnd.Intn(len(failoverUnits))]
+	a.FailoverReason = failoverReasons[a.rnd.Intn(len(failoverReasons))]
+	a.Service = services[a.rnd.Intn(len(services))]
+	a.DropObject = dropObjects[a.rnd.Intn(len(dropObjects))]
+	a.RateId = 1 + a.rnd.Intn(2)
+	a.MaxBurstRate = 10 * (1 + a.rnd.Intn(10))
+	a.BurstRate = a.MaxBurstRate + 1 + a.rnd.Intn(100)
+	a.MaxAvgRate = a.MaxBurstRate / 2
+	a.AvgRate = a.MaxAvgRate + 1 + a.rnd.Intn(50)
+	a.TotalCount = a.BurstRate * (1 + a.rnd.Intn(600))
+	a.RuleAction = ruleActions[a.rnd.Intn(len(ruleActions))]
+	a.AppProtocol = appProtocols[a.rnd.Intn(len(appProtocols))]
+	a.Intrusion = intrusions[a.rnd.Intn(len(intrusions))]
+	a.InlineResult = inlineResults[a.rnd.Intn(len(inlineResults))]
+	a.File = files[a.rnd.Intn(len(files))]
+	a.FileAction = fileActions[a.rnd.Intn(len(fileActions))]
+	a.FileSize = a.rnd.Intn(1 << 22)
+	sha := make([]byte, 32)
+	a.rnd.Read(sha)
+	a.FileSha256 = hex.EncodeToString(sha)
 }
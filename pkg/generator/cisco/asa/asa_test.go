@@ -2,10 +2,15 @@ package asa
 
 import (
 	"math/rand"
+	"regexp"
 	"testing"
 	"text/template"
+	"time"
 
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,20 +19,97 @@ func TestNext(t *testing.T) {
 		template string
 		expected string
 	}{
-		"106023": {template: asa106023, expected: "%ASA-4-106023: Deny udp src SrcInt:144.254.210.24/18340 dst DstInt:141.249.228.131/23215 type 34 code 49 by access-group \"AclId\" [0x8ed66b60, 0xf8852875]"},
-		"302013": {template: asa302013, expected: "%ASA-6-302013: Built inbound TCP connection 19911 for SrcInt:144.254.210.24/18340 (53.42.9.120/30347) to DstInt:141.249.228.131/23215 (43.185.8.75/16165)"},
-		"302014": {template: asa302014, expected: "%ASA-6-302014: Teardown TCP connection 19911 for SrcInt:144.254.210.24/18340 to DstInt:141.249.228.131/23215 duration 3:01:18 bytes 52025 Xlate Clear"},
-		"305011": {template: asa305011, expected: "%ASA-6-305011: Built static UDP translation from SrcInt:144.254.210.24/18340 to DstInt:141.249.228.131/23215"},
+		"106023": {template: asa106023, expected: "%ASA-4-106023: Deny udp src SrcInt:114.150.205.16/53932 dst DstInt:144.254.210.24/18340 type 6 code 47 by access-group \"AclId\" [0x8ed66b60, 0xf8852875]"},
+		"302013": {template: asa302013, expected: "%ASA-6-302013: Built outbound TCP connection 0 for SrcInt:114.150.205.16/53932 (176.66.108.81/38170) to DstInt:144.254.210.24/18340 (22.237.116.72/23701)"},
+		"302014": {template: asa302014, expected: "%ASA-6-302014: Teardown TCP connection 0 for SrcInt:114.150.205.16/53932 to DstInt:144.254.210.24/18340 duration 1:30:47 bytes 1211 TCP unexpected window size variation"},
+		"305011": {template: asa305011, expected: "%ASA-6-305011: Built static UDP translation from SrcInt:114.150.205.16/53932 to DstInt:144.254.210.24/18340"},
+		"106100": {template: asa106100, expected: "%ASA-6-106100: access-list AclId permitted udp SrcInt/114.150.205.16(53932) -> DstInt/144.254.210.24(18340) hit-cnt 48 300-second interval [0x9217a4d1, 0x0]"},
+		"113019": {template: asa113019, expected: "%ASA-4-113019: Group = DfltGrpPolicy, Username = user01, IP = 114.150.205.16, Session disconnected. Session Type: AnyConnect-Parent, Duration: 1h:30m:47s, Bytes xmt: 1211, Bytes rcv: 689537, Reason: User Requested"},
+		"302020": {template: asa302020, expected: "%ASA-6-302020: Built outbound ICMP connection for faddr 144.254.210.24/53932 gaddr 176.66.108.81/0 laddr 114.150.205.16/0 type 8 code 0"},
+		"722051": {template: asa722051, expected: "%ASA-4-722051: Group <DfltGrpPolicy> User <user01> IP <114.150.205.16> IPv4 Address <176.66.108.81> IPv6 address <::> assigned to session"},
+		"733100": {template: asa733100, expected: "%ASA-4-733100: [ Syn Attack] drop rate-2 exceeded. Current burst rate is 108 per second, max configured rate is 70; Current average rate is 67 per second, max configured rate is 35; Cumulative total count is 30888"},
+		"430002": {template: ftd430002, expected: "%FTD-1-430002: DeviceUUID: , InstanceID: 1, FirstPacketSecond: 1970-01-02T01:33:18Z, ConnectionID: 0, AccessControlRuleAction: Allow, SrcIP: 114.150.205.16, DstIP: 144.254.210.24, SrcPort: 53932, DstPort: 18340, Protocol: udp, IngressInterface: SrcInt, EgressInterface: DstInt, IngressZone: SrcZone, EgressZone: DstZone, ACPolicy: AcPolicy, AccessControlRuleName: AclId, Prefilter Policy: Default Prefilter Policy, User: user01, InitiatorPackets: 1, ResponderPackets: 0, InitiatorBytes: 66, ResponderBytes: 0, NAPPolicy: Balanced Security and Connectivity"},
 	}
+	testTime, err := time.Parse(time.RFC3339, "1970-01-02T03:04:05Z")
+	assert.Nil(t, err)
 	for name, tc := range tests {
-		rand.Seed(1)
-		a := &Asa{}
+		a := &Asa{rnd: rand.New(rand.NewSource(1)), clock: clock.Fixed(testTime)}
 		templ, err := template.New(name).Funcs(generator.FunctionMap).Parse(tc.template)
 		assert.Nil(t, err)
+		a.ids = []string{name}
 		a.templates = []*template.Template{templ}
+		a.weighted, err = random.NewWeighted([]float64{1})
+		assert.Nil(t, err)
 		a.randomize()
 		got, err := a.Next()
 		assert.Nil(t, err, name)
-		assert.Equal(t, []byte(tc.expected), got, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
+
+func TestMessages(t *testing.T) {
+	for _, m := range messages {
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "message_ids": []string{m.id}, "include_timestamp": true})
+		assert.Nil(t, err, m.id)
+		g, err := New(c, rand.New(rand.NewSource(1)), clock.Real)
+		assert.Nil(t, err, m.id)
+		got, err := g.Next()
+		assert.Nil(t, err, m.id)
+		assert.Regexp(t, `^\w{3} \d{2} \d{4} \d{2}:\d{2}:\d{2}: %(ASA|FTD)-\d-`+m.id+`: `, string(got), m.id)
+		assert.NotContains(t, string(got), "<no value>", m.id)
+	}
+}
+
+func TestConnections(t *testing.T) {
+	tests := map[string]struct {
+		build    string
+		teardown string
+		id       *regexp.Regexp
+	}{
+		"TCP": {build: "302013", teardown: "302014", id: regexp.MustCompile(`connection (\d+) for (\S+)`)},
+		"UDP": {build: "302015", teardown: "302016", id: regexp.MustCompile(`connection (\d+) for (\S+)`)},
+		"FTD": {build: "430002", teardown: "430003", id: regexp.MustCompile(`ConnectionID: (\d+), .* SrcIP: (\S+)`)},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "message_ids": []string{tc.build, tc.teardown}})
+		assert.Nil(t, err, name)
+		g, err := New(c, rand.New(rand.NewSource(1)), clock.Real)
+		assert.Nil(t, err, name)
+
+		// open maps the ID of each connection that is built and not
+		// yet torn down to its source.
+		open := make(map[string]string)
+		var teardowns int
+		for i := 0; i < 1000; i++ {
+			got, err := g.Next()
+			assert.Nil(t, err, name)
+			m := tc.id.FindStringSubmatch(string(got))
+			if !assert.NotNil(t, m, "%s: %s", name, got) {
+				continue
+			}
+			id, src := m[1], m[2]
+			if regexp.MustCompile(tc.build).Match(got) {
+				open[id] = src
+				continue
+			}
+			if s, ok := open[id]; ok {
+				assert.Equal(t, s, src, name)
+				delete(open, id)
+				teardowns++
+			}
+		}
+		assert.Greater(t, teardowns, 400, name)
+	}
+}
+
+func TestWeights(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "message_ids": []string{"106023", "302013"}, "weights": []float64{0, 1}})
+	assert.Nil(t, err)
+	g, err := New(c, rand.New(rand.NewSource(1)), clock.Real)
+	assert.Nil(t, err)
+	for i := 0; i < 100; i++ {
+		got, err := g.Next()
+		assert.Nil(t, err)
+		assert.Contains(t, string(got), "%ASA-6-302013: ")
 	}
 }
@@ -1,10 +1,15 @@
 package asa
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type config struct {
-	Type             string `config:"type" validate:"required"`
-	IncludeTimestamp bool   `config:"include_timestamp"`
+	Type             string    `config:"type" validate:"required"`
+	IncludeTimestamp bool      `config:"include_timestamp"`
+	MessageIds       []string  `config:"message_ids"`
+	Weights          []float64 `config:"weights"`
 }
 
 func defaultConfig() config {
@@ -17,5 +22,26 @@ func (c *config) Validate() error {
 	if c.Type != Name {
 		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
 	}
+	for _, id := range c.MessageIds {
+		if messageTemplate(id) == "" {
+			return fmt.Errorf("'%s' is not a valid value for 'message_ids' expected one of %s", id, strings.Join(messageIds(), ", "))
+		}
+	}
+	if len(c.Weights) == 0 {
+		return nil
+	}
+	if len(c.Weights) != len(c.MessageIds) {
+		return fmt.Errorf("'weights' has %d values expected one for each of the %d 'message_ids'", len(c.Weights), len(c.MessageIds))
+	}
+	var total float64
+	for i, w := range c.Weights {
+		if w < 0 {
+			return fmt.Errorf("'%v' is not a valid value for 'weights.%d' expected >= 0", w, i)
+		}
+		total += w
+	}
+	if total <= 0 {
+		return fmt.Errorf("at least one of 'weights' must be > 0")
+	}
 	return nil
 }
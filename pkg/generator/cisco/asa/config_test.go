@@ -1,9 +1,12 @@
 package asa
 
 import (
+	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -33,11 +36,41 @@ func TestConfigs(t *testing.T) {
 			hasError:    false,
 			errorString: "",
 		},
+		"Message IDs": {
+			c:           map[string]interface{}{"type": Name, "message_ids": []interface{}{302013, "302014"}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Message ID": {
+			c:           map[string]interface{}{"type": Name, "message_ids": []string{"302013", "999999"}},
+			hasError:    true,
+			errorString: "'999999' is not a valid value for 'message_ids' expected one of " + strings.Join(messageIds(), ", ") + " accessing config",
+		},
+		"Weights": {
+			c:           map[string]interface{}{"type": Name, "message_ids": []string{"302013", "302014"}, "weights": []float64{3, 1}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Weights Without Message IDs": {
+			c:           map[string]interface{}{"type": Name, "weights": []float64{3, 1}},
+			hasError:    true,
+			errorString: "'weights' has 2 values expected one for each of the 0 'message_ids' accessing config",
+		},
+		"Negative Weight": {
+			c:           map[string]interface{}{"type": Name, "message_ids": []string{"302013", "302014"}, "weights": []float64{3, -1}},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'weights.1' expected >= 0 accessing config",
+		},
+		"Zero Weights": {
+			c:           map[string]interface{}{"type": Name, "message_ids": []string{"302013"}, "weights": []float64{0}},
+			hasError:    true,
+			errorString: "at least one of 'weights' must be > 0 accessing config",
+		},
 	}
 	for name, tc := range tests {
 		c, err := ucfg.NewFrom(tc.c)
 		assert.Nil(t, err, name)
-		_, err = New(c)
+		_, err = New(c, rand.New(rand.NewSource(1)), clock.Real)
 		if tc.hasError {
 			assert.NotNil(t, err, name)
 			assert.Equal(t, err.Error(), tc.errorString, name)
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/leehinman/spigot/pkg/generator"
 	"github.com/leehinman/spigot/pkg/random"
 )
@@ -138,20 +139,26 @@ type CEF struct {
 	Action            string
 
 	templates []*template.Template
+	rnd       *rand.Rand
+	clock     clock.Clock
 }
 
 func init() {
 	generator.Register(Name, New)
+	generator.RegisterInfo(Name, generator.Info{
+		Description: "Citrix NetScaler application firewall CEF messages.",
+		Config:      defaultConfig(),
+	})
 }
 
 // New returns a new Citrix CEF log line generator.
-func New(cfg *ucfg.Config) (generator.Generator, error) {
+func New(cfg *ucfg.Config, r *rand.Rand, clk clock.Clock) (generator.Generator, error) {
 	def := defaultConfig()
 	if err := cfg.Unpack(&def); err != nil {
 		return nil, err
 	}
 
-	c := &CEF{}
+	c := &CEF{rnd: r, clock: clk}
 	c.randomize()
 
 	for i, v := range msgTemplates {
@@ -169,7 +176,7 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 func (c *CEF) Next() ([]byte, error) {
 	var buf bytes.Buffer
 
-	err := c.templates[rand.Intn(len(c.templates))].Execute(&buf, c)
+	err := c.templates[c.rnd.Intn(len(c.templates))].Execute(&buf, c)
 	if err != nil {
 		return nil, err
 	}
@@ -180,41 +187,41 @@ func (c *CEF) Next() ([]byte, error) {
 }
 
 func (c *CEF) randomize() {
-	c.Timestamp = time.Now()
-	c.TimeLayout = randString(timeLayouts)
-
-	c.Facility = randString(facilities)
-	c.Priority = randString(priorities)
-
-	c.Addr = random.IPv4()
-
-	c.CEFVersion = rand.Intn(2)
-	c.Vendor = randString(vendors)
-	c.Product = randString(products)
-	c.Version = randString(versions)
-	c.Module = randString(modules)
-	c.Violation = randString(violations)
-	c.Severity = rand.Intn(10) + 1
-
-	c.SrcAddr = random.IPv4()
-	c.Geo = randString(locations)
-	c.SrcPort = random.Port()
-	c.Method = randString(methods)
-	c.Request = randString(requests)
-	c.Message = randString(messages)
-	c.EventID = rand.Intn(1000)
-	c.TxID = rand.Intn(100000)
-	c.Profile = randString(profiles)
-	c.PPEID = fmt.Sprintf("PPE%d", rand.Intn(9)+1)
+	c.Timestamp = c.clock.Now()
+	c.TimeLayout = randString(c.rnd, timeLayouts)
+
+	c.Facility = randString(c.rnd, facilities)
+	c.Priority = randString(c.rnd, priorities)
+
+	c.Addr = random.IPv4(c.rnd)
+
+	c.CEFVersion = c.rnd.Intn(2)
+	c.Vendor = randString(c.rnd, vendors)
+	c.Product = randString(c.rnd, products)
+	c.Version = randString(c.rnd, versions)
+	c.Module = randString(c.rnd, modules)
+	c.Violation = randString(c.rnd, violations)
+	c.Severity = c.rnd.Intn(10) + 1
+
+	c.SrcAddr = random.IPv4(c.rnd)
+	c.Geo = randString(c.rnd, locations)
+	c.SrcPort = random.Port(c.rnd)
+	c.Method = randString(c.rnd, methods)
+	c.Request = randString(c.rnd, requests)
+	c.Message = randString(c.rnd, messages)
+	c.EventID = c.rnd.Intn(1000)
+	c.TxID = c.rnd.Intn(100000)
+	c.Profile = randString(c.rnd, profiles)
+	c.PPEID = fmt.Sprintf("PPE%d", c.rnd.Intn(9)+1)
 	sessID := make([]byte, 16)
-	rand.Read(sessID)
+	c.rnd.Read(sessID)
 	c.SessID = hex.EncodeToString(sessID)
-	c.SeverityLabel = randString(severityLabels)
+	c.SeverityLabel = randString(c.rnd, severityLabels)
 	c.Year = c.Timestamp.Year()
-	c.ViolationCategory = randString(violationCategory)
-	c.Action = randString(actions)
+	c.ViolationCategory = randString(c.rnd, violationCategory)
+	c.Action = randString(c.rnd, actions)
 }
 
-func randString(s []string) string {
-	return s[rand.Intn(len(s))]
+func randString(r *rand.Rand, s []string) string {
+	return s[r.Intn(len(s))]
 }
@@ -6,6 +6,7 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/leehinman/spigot/pkg/generator"
 )
 
@@ -18,7 +19,7 @@ func TestNext(t *testing.T) {
 		{seed: 3, want: `Jan 02 03:04:05 <local5.error> 244.161.164.196 CEF:1|Citrix|NetScalar|NS11.0|APPFW|APPFW_SAFECOMMERCE_XFORM|7|src=157.155.176.203 geolocation=Unknown spt=29735 method=GET request=http://aaron.stratum8.net/FFC/wwwboard/passwd.txt msg=Field consistency check failed for field passwd cn1=278 cn2=29074 cs1=pr_ffc cs2=PPE4 cs3=06d37841b74bcbbdf8987a19dcddc8e9 cs4=ALERT cs5=2022 cs6=web-cgi act=blocked`},
 		{seed: 4, want: `Jan 2 03:04:05 <local4.error> 63.132.159.242 CEF:1|Citrix|NetScalar|NS11.0|APPFW|APPFW_SAFECOMMERCE|8|src=201.132.96.184 spt=25717 method=GET request=http://aaron.stratum8.net/FFC/wwwboard/passwd.txt msg=Field consistency check failed for field passwd cn1=586 cn2=78840 cs1=pr_ffc cs2=PPE8 cs3=ab6d79345fe5e99adf9ddd3d1dbfe5db cs4=INFO cs5=2022 cs6=sql-injection act=transformed`},
 	}
-	now, err := time.Parse(time.RFC3339, "1970-01-02T03:04:05Z")
+	now, err := time.Parse(time.RFC3339, "2022-01-02T03:04:05Z")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -26,11 +27,10 @@ func TestNext(t *testing.T) {
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
-	c := &CEF{templates: []*template.Template{templ}}
+	c := &CEF{templates: []*template.Template{templ}, clock: clock.Fixed(now)}
 	for _, test := range tests {
-		rand.Seed(test.seed)
+		c.rnd = rand.New(rand.NewSource(test.seed))
 		c.randomize()
-		c.Timestamp = now
 		got, err := c.Next()
 		if err != nil {
 			t.Errorf("unexpected error for c.Next() with seed=%d: %v", err, test.seed)
@@ -17,9 +17,9 @@ import (
 	"net"
 	"strconv"
 	"text/template"
-	"time"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/leehinman/spigot/pkg/generator"
 	"github.com/leehinman/spigot/pkg/random"
 )
@@ -50,10 +50,11 @@ type Record struct {
 type Generator struct {
 	Record Record
 
-	tmpl       *template.Template
-	staticTime *time.Time
-	buf        bytes.Buffer
-	combined   bool
+	tmpl     *template.Template
+	buf      bytes.Buffer
+	combined bool
+	rnd      *rand.Rand
+	clock    clock.Clock
 }
 
 // Next produces the next Common Log Format record.
@@ -73,33 +74,30 @@ func (g *Generator) Next() ([]byte, error) {
 }
 
 func (g *Generator) randomize() {
-	now := time.Now()
-	if g.staticTime != nil {
-		now = *g.staticTime
-	}
+	now := g.clock.Now()
 
 	g.Record = Record{
-		Host:     random.IPv4(),
+		Host:     random.IPv4(g.rnd),
 		Ident:    "-",
 		AuthUser: "-",
 		Date:     now.Format(timestampFmt),
 		Request: fmt.Sprintf(
 			`"%s %s %s"`,
-			random.HTTPMethod(),
-			fmt.Sprintf("/random-%d.html", rand.Intn(100)),
-			random.HTTPVersion(),
+			random.HTTPMethod(g.rnd),
+			fmt.Sprintf("/random-%d.html", g.rnd.Intn(100)),
+			random.HTTPVersion(g.rnd),
 		),
-		Status: strconv.Itoa(random.HTTPStatus()),
-		Bytes:  strconv.Itoa(rand.Intn(10000)),
+		Status: strconv.Itoa(random.HTTPStatus(g.rnd)),
+		Bytes:  strconv.Itoa(g.rnd.Intn(10000)),
 	}
 	if g.combined {
 		g.Record.Referer = "-"
-		g.Record.UserAgent = `"` + random.UserAgent() + `"`
+		g.Record.UserAgent = `"` + random.UserAgent(g.rnd) + `"`
 	}
 }
 
 // New is the factory for Common Log Format objects.
-func New(cfg *ucfg.Config) (generator.Generator, error) {
+func New(cfg *ucfg.Config, r *rand.Rand, clk clock.Clock) (generator.Generator, error) {
 	var err error
 
 	c := defaultConfig()
@@ -109,6 +107,8 @@ func New(cfg *ucfg.Config) (generator.Generator, error) {
 
 	g := Generator{
 		combined: c.Combined,
+		rnd:      r,
+		clock:    clk,
 	}
 
 	if g.combined {
@@ -127,4 +127,8 @@ func init() {
 	if err := generator.Register(Name, New); err != nil {
 		panic(err)
 	}
+	generator.RegisterInfo(Name, generator.Info{
+		Description: "Web server access logs in Common or Combined Log Format.",
+		Config:      defaultConfig(),
+	})
 }
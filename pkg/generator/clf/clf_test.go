@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,13 +31,9 @@ func TestGenerator_Next(t *testing.T) {
 	for name, tc := range tests {
 		tc := tc
 		t.Run(name, func(t *testing.T) {
-			rand.Seed(1)
-
-			g, err := New(ucfg.MustNewFrom(tc.config))
+			g, err := New(ucfg.MustNewFrom(tc.config), rand.New(rand.NewSource(1)), clock.Fixed(testTime))
 			assert.NoError(t, err)
 
-			g.(*Generator).staticTime = &testTime
-
 			got, err := g.Next()
 			assert.NoError(t, err)
 
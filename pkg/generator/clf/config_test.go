@@ -1,9 +1,11 @@
 package clf
 
 import (
+	"math/rand"
 	"testing"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,7 +39,7 @@ func TestConfigs(t *testing.T) {
 			cfg, err := ucfg.NewFrom(tc.config)
 			assert.NoError(t, err)
 
-			_, err = New(cfg)
+			_, err = New(cfg, rand.New(rand.NewSource(1)), clock.Real)
 			if tc.hasError {
 				assert.Error(t, err)
 				assert.Equal(t, err.Error(), tc.errorString)
@@ -1,14 +1,22 @@
 package firewall
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type config struct {
-	Type string `config:"type" validate:"required"`
+	Type         string    `config:"type" validate:"required"`
+	Subtypes     []string  `config:"subtypes"`
+	Weights      []float64 `config:"weights"`
+	Version      string    `config:"version"`
+	SyslogHeader string    `config:"syslog_header"`
 }
 
 func defaultConfig() config {
 	return config{
-		Type: Name,
+		Type:    Name,
+		Version: Version6,
 	}
 }
 
@@ -16,5 +24,41 @@ func (c *config) Validate() error {
 	if c.Type != Name {
 		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
 	}
+	seen := make(map[string]bool)
+	for _, s := range c.Subtypes {
+		if _, ok := findSubtype(s); !ok {
+			return fmt.Errorf("'%s' is not a valid value for 'subtypes' expected one of %s", s, strings.Join(subtypeNames(), ", "))
+		}
+		if seen[s] {
+			return fmt.Errorf("'%s' is repeated in 'subtypes'", s)
+		}
+		seen[s] = true
+	}
+	switch c.Version {
+	case Version6, Version7:
+	default:
+		return fmt.Errorf("'%s' is not a valid value for 'version' expected one of %s, %s", c.Version, Version6, Version7)
+	}
+	switch c.SyslogHeader {
+	case "", SyslogDefault, SyslogRFC5424:
+	default:
+		return fmt.Errorf("'%s' is not a valid value for 'syslog_header' expected one of %s, %s", c.SyslogHeader, SyslogDefault, SyslogRFC5424)
+	}
+	if len(c.Weights) == 0 {
+		return nil
+	}
+	if len(c.Weights) != len(c.Subtypes) {
+		return fmt.Errorf("'weights' has %d values expected one for each of the %d 'subtypes'", len(c.Weights), len(c.Subtypes))
+	}
+	var total float64
+	for i, w := range c.Weights {
+		if w < 0 {
+			return fmt.Errorf("'%v' is not a valid value for 'weights.%d' expected >= 0", w, i)
+		}
+		total += w
+	}
+	if total <= 0 {
+		return fmt.Errorf("at least one of 'weights' must be > 0")
+	}
 	return nil
 }
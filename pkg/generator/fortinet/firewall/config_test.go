@@ -1,9 +1,12 @@
 package firewall
 
 import (
+	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,11 +31,66 @@ func TestConfigs(t *testing.T) {
 			hasError:    true,
 			errorString: "string value is not set accessing 'type'",
 		},
+		"Subtypes": {
+			c:           map[string]interface{}{"type": Name, "subtypes": []string{"forward", "app-ctrl", "vpn"}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Subtype": {
+			c:           map[string]interface{}{"type": Name, "subtypes": []string{"forward", "bob"}},
+			hasError:    true,
+			errorString: "'bob' is not a valid value for 'subtypes' expected one of " + strings.Join(subtypeNames(), ", ") + " accessing config",
+		},
+		"Repeated Subtype": {
+			c:           map[string]interface{}{"type": Name, "subtypes": []string{"forward", "forward"}},
+			hasError:    true,
+			errorString: "'forward' is repeated in 'subtypes' accessing config",
+		},
+		"Weights": {
+			c:           map[string]interface{}{"type": Name, "subtypes": []string{"forward", "ips"}, "weights": []float64{9, 1}},
+			hasError:    false,
+			errorString: "",
+		},
+		"Weights Without Subtypes": {
+			c:           map[string]interface{}{"type": Name, "weights": []float64{9, 1}},
+			hasError:    true,
+			errorString: "'weights' has 2 values expected one for each of the 0 'subtypes' accessing config",
+		},
+		"Negative Weight": {
+			c:           map[string]interface{}{"type": Name, "subtypes": []string{"forward", "ips"}, "weights": []float64{9, -1}},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'weights.1' expected >= 0 accessing config",
+		},
+		"Zero Weights": {
+			c:           map[string]interface{}{"type": Name, "subtypes": []string{"forward"}, "weights": []float64{0}},
+			hasError:    true,
+			errorString: "at least one of 'weights' must be > 0 accessing config",
+		},
+		"Version 7": {
+			c:           map[string]interface{}{"type": Name, "version": 7},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Version": {
+			c:           map[string]interface{}{"type": Name, "version": "5"},
+			hasError:    true,
+			errorString: "'5' is not a valid value for 'version' expected one of 6, 7 accessing config",
+		},
+		"Syslog Header": {
+			c:           map[string]interface{}{"type": Name, "syslog_header": "rfc5424"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Syslog Header": {
+			c:           map[string]interface{}{"type": Name, "syslog_header": "rfc3164"},
+			hasError:    true,
+			errorString: "'rfc3164' is not a valid value for 'syslog_header' expected one of default, rfc5424 accessing config",
+		},
 	}
 	for name, tc := range tests {
 		c, err := ucfg.NewFrom(tc.c)
 		assert.Nil(t, err, name)
-		_, err = New(c)
+		_, err = New(c, rand.New(rand.NewSource(1)), clock.Real)
 		if tc.hasError {
 			assert.NotNil(t, err, name)
 			assert.Equal(t, err.Error(), tc.errorString, name)
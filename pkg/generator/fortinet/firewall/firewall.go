@@ -1,20 +1,36 @@
-// Package firewall generates Fortinet Firewall log messages
+// Package firewall generates Fortinet FortiGate log messages
 //
-// For the configuration file there are no options so only the following is needed:
+// Configuration:
 //
-//   - generator:
-//       type: "fortinet:firewall"
+//	subtypes:      The subtypes to generate, or leave blank for all.  Valid
+//	               values are: forward, local, multicast (type traffic),
+//	               virus, webfilter, ips, app-ctrl, ssl, anomaly, dns (type
+//	               utm) and vpn, ha, system, user (type event).
+//	weights:       Optional, one weight for each of "subtypes".  Subtypes
+//	               are picked with probability proportional to their weight.
+//	version:       The FortiOS log format, "6" (default) or "7".  FortiOS 7
+//	               logs have a "logver" and an "eventtime" in nanoseconds.
+//	syslog_header: Optional, wraps each log in a syslog header as FortiOS
+//	               sends it.  "default" prefixes the priority, "rfc5424"
+//	               writes a full RFC 5424 header.
+//
+//	- generator:
+//	    type: "fortinet:firewall"
+//	    subtypes: [forward, webfilter, ips]
+//	    weights: [8, 1, 1]
+//	    version: 7
 package firewall
 
 import (
 	"bytes"
+	"fmt"
 	"math/rand"
 	"net"
-	"strconv"
 	"text/template"
 	"time"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/leehinman/spigot/pkg/generator"
 	"github.com/leehinman/spigot/pkg/random"
 )
@@ -22,19 +38,67 @@ import (
 // Name is the name used in the configuration file and the registry.
 const Name = "fortinet:firewall"
 
+const (
+	Version6 = "6"
+	Version7 = "7"
+
+	SyslogDefault = "default"
+	SyslogRFC5424 = "rfc5424"
+)
+
+// logVer is the "logver" of FortiOS 7 logs, FortiOS 7.0.14 build 665.
+const logVer = "700140665"
+
+// facility is the syslog facility FortiOS uses by default, local7.
+const facility = 23
+
+// timezone is the time zone the FortiGate is configured with, "date",
+// "time" and "tz" are in this zone.
+var timezone = time.FixedZone("", -5*60*60)
+
 var (
-	eventUserTemplate      = "date={{.Date.UTC.Format \"2006-01-02\"}} time={{.Date.UTC.Format \"03:04:05\"}} devname=\"{{.DevName}}\" devid=\"{{.DevId}}\" logid=\"{{.LogId}}\" type=\"event\" subtype=\"user\" level=\"{{.Level}}\" vd=\"{{.Vd}}\" eventtime={{.Date.Unix}} tz=\"{{.Timezone}}\" logdesc=\"FSSO logon authentication status\" srcip={{.SrcIp}} user=\"{{.User}}\" server=\"{{.Server}}\" action=\"FSSO-logon\" msg=\"FSSO-logon event from FSSO_{{.Server}}: user {{.User}} logged on {{.SrcIp}}\""
-	eventSystemTemplate    = "date={{.Date.UTC.Format \"2006-01-02\"}} time={{.Date.UTC.Format \"03:04:05\"}} devname=\"{{.DevName}}\" devid=\"{{.DevId}}\" logid=\"{{.LogId}}\" type=\"event\" subtype=\"system\" level=\"{{.Level}}\" vd=\"{{.Vd}}\" eventtime={{.Date.Unix}} tz=\"{{.Timezone}}\" logdesc=\"FortiSandbox AV database updated\" version=\"1.522479\" msg=\"FortiSandbox AV database updated\""
-	utmDnsTemplate         = "date={{.Date.UTC.Format \"2006-01-02\"}} time={{.Date.UTC.Format \"03:04:05\"}} devname=\"{{.DevName}}\" devid=\"{{.DevId}}\" logid=\"{{.LogId}}\" type=\"utm\" subtype=\"dns\" eventtype=\"dns-query\" level=\"{{.Level}}\" vd=\"{{.Vd}}\" eventtime={{.Date.Unix}} tz=\"{{.Timezone}}\" policyid={{.PolicyId}} sessionid={{.SessionId}} srcip={{.SrcIp}} srcport={{.SrcPort}} srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstip={{.DstIp}} dstport=53 dstintf=\"{{.Interface2}}\" dstintfrole=\"{{.InterfaceRole2}}\" proto={{.Protocol}} profile=\"elastictest\" xid={{.XId}} qname=\"{{.QueryName}}\" qtype=\"{{.QueryType}}\" qtypeval=1 qclass=\"IN\""
-	trafficForwardTemplate = "date={{.Date.UTC.Format \"2006-01-02\"}} time={{.Date.UTC.Format \"03:04:05\"}} devname=\"{{.DevName}}\" devid=\"{{.DevId}}\" logid=\"{{.LogId}}\" type=\"traffic\" subtype=\"forward\" level=\"{{.Level}}\" vd=\"{{.Vd}}\" eventtime={{.Date.Unix}} srcip={{.SrcIp}} srcport={{.SrcPort}} srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstip={{.DstIp}} dstport={{.DstPort}} dstintf=\"{{.Interface2}}\" dstintfrole=\"{{.InterfaceRole2}}\" sessionid={{.SessionId}} proto={{.Protocol}} action=\"{{.TrafficAction}}\" policyid={{.PolicyId}} policytype=\"policy\" service=\"SNMP\" dstcountry=\"Reserved\" srccountry=\"Reserved\" trandisp=\"noop\" duration={{.Duration}} sentbyte={{.SentBytes}} rcvdbyte={{.SentBytes}} sentpkt={{.SentPackets}} appcat=\"unscanned\" crscore=30 craction=131072 crlevel=\"high\""
-	msgTemplates           = [...]string{
-		eventUserTemplate,
-		eventSystemTemplate,
-		utmDnsTemplate,
-		trafficForwardTemplate,
+	header6 = "date={{.Date.Format \"2006-01-02\"}} time={{.Date.Format \"15:04:05\"}} devname=\"{{.DevName}}\" devid=\"{{.DevId}}\" logid=\"{{.LogId}}\" type=\"{{.Type}}\" subtype=\"{{.Subtype}}\"{{if .EventType}} eventtype=\"{{.EventType}}\"{{end}} level=\"{{.Level}}\" vd=\"{{.Vd}}\" eventtime={{.Date.Unix}} tz=\"{{.Timezone}}\" "
+	header7 = "date={{.Date.Format \"2006-01-02\"}} time={{.Date.Format \"15:04:05\"}} devname=\"{{.DevName}}\" devid=\"{{.DevId}}\" logver=" + logVer + " eventtime={{.Date.UnixNano}} tz=\"{{.Timezone}}\" logid=\"{{.LogId}}\" type=\"{{.Type}}\" subtype=\"{{.Subtype}}\"{{if .EventType}} eventtype=\"{{.EventType}}\"{{end}} level=\"{{.Level}}\" vd=\"{{.Vd}}\" "
+
+	trafficForwardTemplate   = "srcip={{.SrcIp}} srcport={{.SrcPort}} srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstip={{.DstIp}} dstport={{.DstPort}} dstintf=\"{{.Interface2}}\" dstintfrole=\"{{.InterfaceRole2}}\" sessionid={{.SessionId}} proto={{.Protocol}} action=\"{{.TrafficAction}}\" policyid={{.PolicyId}} policytype=\"policy\" service=\"SNMP\" dstcountry=\"Reserved\" srccountry=\"Reserved\" trandisp=\"noop\" duration={{.Duration}} sentbyte={{.SentBytes}} rcvdbyte={{.ReceivedBytes}} sentpkt={{.SentPackets}} rcvdpkt={{.ReceivedPackets}} appcat=\"unscanned\" crscore=30 craction=131072 crlevel=\"high\""
+	trafficLocalTemplate     = "srcip={{.SrcIp}} srcport={{.SrcPort}} srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstip={{.DstIp}} dstport={{.DstPort}} dstintf=\"root\" dstintfrole=\"undefined\" sessionid={{.SessionId}} proto={{.Protocol}} action=\"{{.TrafficAction}}\" policyid=0 policytype=\"local-in-policy\" service=\"{{.Service}}\" dstcountry=\"Reserved\" srccountry=\"Reserved\" trandisp=\"noop\" app=\"{{.Service}}\" duration={{.Duration}} sentbyte={{.SentBytes}} rcvdbyte={{.ReceivedBytes}} sentpkt={{.SentPackets}} rcvdpkt={{.ReceivedPackets}} appcat=\"unscanned\""
+	trafficMulticastTemplate = "srcip={{.SrcIp}} srcport={{.SrcPort}} srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstip={{.MulticastIp}} dstport={{.DstPort}} dstintf=\"{{.Interface2}}\" dstintfrole=\"{{.InterfaceRole2}}\" sessionid={{.SessionId}} proto=17 action=\"accept\" policyid={{.PolicyId}} policytype=\"multicast-policy\" service=\"udp/{{.DstPort}}\" dstcountry=\"Reserved\" srccountry=\"Reserved\" trandisp=\"noop\" duration={{.Duration}} sentbyte={{.SentBytes}} rcvdbyte=0 sentpkt={{.SentPackets}} rcvdpkt=0"
+	utmVirusTemplate         = "policyid={{.PolicyId}} msg=\"File is infected.\" action=\"{{.VirusAction}}\" service=\"HTTP\" sessionid={{.SessionId}} srcip={{.SrcIp}} dstip={{.DstIp}} srcport={{.SrcPort}} dstport=80 srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstintf=\"{{.Interface2}}\" dstintfrole=\"{{.InterfaceRole2}}\" proto=6 direction=\"incoming\" filename=\"{{.Virus.File}}\" quarskip=\"File-was-not-quarantined\" virus=\"{{.Virus.Name}}\" dtype=\"Virus\" ref=\"http://www.fortinet.com/ve?vn={{.Virus.Name}}\" virusid={{.Virus.Id}} url=\"http://{{.QueryName}}/{{.Virus.File}}\" profile=\"default\" agent=\"{{.UserAgent}}\" analyticssubmit=\"false\" crscore=50 craction=2 crlevel=\"critical\""
+	utmWebfilterTemplate     = "policyid={{.PolicyId}} sessionid={{.SessionId}} srcip={{.SrcIp}} srcport={{.SrcPort}} srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstip={{.DstIp}} dstport=443 dstintf=\"{{.Interface2}}\" dstintfrole=\"{{.InterfaceRole2}}\" proto=6 service=\"HTTPS\" hostname=\"{{.QueryName}}\" profile=\"default\" action=\"{{.Category.Action}}\" reqtype=\"direct\" url=\"https://{{.QueryName}}/\" sentbyte={{.SentBytes}} rcvdbyte={{.ReceivedBytes}} direction=\"outgoing\" msg=\"{{.Category.Msg}}\" method=\"domain\" cat={{.Category.Id}} catdesc=\"{{.Category.Desc}}\""
+	utmIpsTemplate           = "severity=\"{{.Attack.Severity}}\" srcip={{.SrcIp}} srccountry=\"Reserved\" dstip={{.DstIp}} dstcountry=\"Reserved\" srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstintf=\"{{.Interface2}}\" dstintfrole=\"{{.InterfaceRole2}}\" sessionid={{.SessionId}} action=\"{{.IpsAction}}\" proto=6 service=\"HTTP\" policyid={{.PolicyId}} attack=\"{{.Attack.Name}}\" srcport={{.SrcPort}} dstport=80 direction=\"outgoing\" attackid={{.Attack.Id}} profile=\"default\" ref=\"http://www.fortinet.com/ids/VID{{.Attack.Id}}\" incidentserialno={{.IncidentSerial}} msg=\"{{.Attack.Category}}: {{.Attack.Name}},\" crscore=50 craction=4096 crlevel=\"critical\""
+	utmAppCtrlTemplate       = "appid={{.App.Id}} srcip={{.SrcIp}} dstip={{.DstIp}} srcport={{.SrcPort}} dstport=443 srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstintf=\"{{.Interface2}}\" dstintfrole=\"{{.InterfaceRole2}}\" proto=6 service=\"HTTPS\" direction=\"outgoing\" policyid={{.PolicyId}} sessionid={{.SessionId}} applist=\"default\" action=\"{{.App.Action}}\" appcat=\"{{.App.Category}}\" app=\"{{.App.Name}}\" hostname=\"{{.QueryName}}\" incidentserialno={{.IncidentSerial}} msg=\"{{.App.Category}}: {{.App.Name}},\" apprisk=\"{{.App.Risk}}\""
+	utmSslTemplate           = "policyid={{.PolicyId}} sessionid={{.SessionId}} service=\"HTTPS\" srcip={{.SrcIp}} srcport={{.SrcPort}} srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstip={{.DstIp}} dstport=443 dstintf=\"{{.Interface2}}\" dstintfrole=\"{{.InterfaceRole2}}\" proto=6 action=\"blocked\" profile=\"certificate-inspection\" hostname=\"{{.QueryName}}\" msg=\"Server certificate blocked\" reason=\"{{.SslReason}}\""
+	utmAnomalyTemplate       = "severity=\"critical\" srcip={{.SrcIp}} srccountry=\"Reserved\" dstip={{.DstIp}} srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" sessionid={{.SessionId}} action=\"{{.AnomalyAction}}\" proto=6 service=\"tcp/{{.DstPort}}\" count={{.Count}} attack=\"{{.Anomaly.Name}}\" srcport={{.SrcPort}} dstport={{.DstPort}} attackid={{.Anomaly.Id}} policyid=1 policytype=\"DoS-policy\" ref=\"http://www.fortinet.com/ids/VID{{.Anomaly.Id}}\" msg=\"anomaly: {{.Anomaly.Name}}, {{.Count}} > threshold {{.Anomaly.Threshold}}\" crscore=50 craction=4096 crlevel=\"critical\""
+	utmDnsTemplate           = "policyid={{.PolicyId}} sessionid={{.SessionId}} srcip={{.SrcIp}} srcport={{.SrcPort}} srcintf=\"{{.Interface1}}\" srcintfrole=\"{{.InterfaceRole1}}\" dstip={{.DstIp}} dstport=53 dstintf=\"{{.Interface2}}\" dstintfrole=\"{{.InterfaceRole2}}\" proto={{.Protocol}} profile=\"elastictest\" xid={{.XId}} qname=\"{{.QueryName}}\" qtype=\"{{.QueryType}}\" qtypeval=1 qclass=\"IN\""
+	eventVpnTemplate         = "logdesc=\"{{.VpnEvent.Desc}}\" msg=\"{{.VpnEvent.Msg}}\" action=\"{{.VpnEvent.Action}}\" remip={{.DstIp}} locip={{.SrcIp}} remport=500 locport=500 outintf=\"{{.Interface2}}\" cookies=\"{{.Cookies}}\" user=\"N/A\" group=\"N/A\" xauthuser=\"N/A\" xauthgroup=\"N/A\" assignip=N/A vpntunnel=\"{{.VpnTunnel}}\" status=\"{{.VpnEvent.Status}}\" init=\"local\" mode=\"main\" dir=\"outbound\" stage=1 role=\"initiator\" result=\"{{.VpnEvent.Result}}\""
+	eventHaTemplate          = "logdesc=\"{{.HaEvent.Desc}}\" msg=\"{{.HaEvent.Msg}}\" ha_group=0 ha_role=\"{{.HaEvent.Role}}\" hbdn_reason=\"{{.HaEvent.Reason}}\" sn=\"{{.DevId}}\""
+	eventSystemTemplate      = "logdesc=\"FortiSandbox AV database updated\" version=\"1.522479\" msg=\"FortiSandbox AV database updated\""
+	eventAdminTemplate       = "logdesc=\"{{.AdminEvent.Desc}}\" sn=\"{{.SessionId}}\" user=\"{{.Admin}}\" ui=\"https({{.SrcIp}})\" method=\"https\" srcip={{.SrcIp}} dstip={{.DstIp}} action=\"{{.AdminEvent.Action}}\" status=\"{{.AdminEvent.Status}}\" reason=\"{{.AdminEvent.Reason}}\" msg=\"{{.AdminEvent.Msg}}\""
+	eventUserTemplate        = "logdesc=\"FSSO logon authentication status\" srcip={{.SrcIp}} user=\"{{.User}}\" server=\"{{.Server}}\" action=\"FSSO-logon\" msg=\"FSSO-logon event from FSSO_{{.Server}}: user {{.User}} logged on {{.SrcIp}}\""
+
+	// subtypes are the supported subtypes with their messages.  A
+	// random message of the subtype is picked for each record.
+	subtypes = [...]subtype{
+		{typ: "traffic", name: "forward", messages: []message{{logId: "0000000013", level: "notice", template: trafficForwardTemplate}}},
+		{typ: "traffic", name: "local", messages: []message{{logId: "0001000014", level: "notice", template: trafficLocalTemplate}}},
+		{typ: "traffic", name: "multicast", messages: []message{{logId: "0002000012", level: "notice", template: trafficMulticastTemplate}}},
+		{typ: "utm", name: "virus", messages: []message{{logId: "0211008192", eventType: "infected", level: "warning", template: utmVirusTemplate}}},
+		{typ: "utm", name: "webfilter", messages: []message{{logId: "0316013056", eventType: "ftgd_blk", level: "warning", template: utmWebfilterTemplate}}},
+		{typ: "utm", name: "ips", messages: []message{{logId: "0419016384", eventType: "signature", level: "alert", template: utmIpsTemplate}}},
+		{typ: "utm", name: "app-ctrl", messages: []message{{logId: "1059028704", eventType: "signature", level: "information", template: utmAppCtrlTemplate}}},
+		{typ: "utm", name: "ssl", messages: []message{{logId: "1700062302", eventType: "ssl-anomaly", level: "warning", template: utmSslTemplate}}},
+		{typ: "utm", name: "anomaly", messages: []message{{logId: "0720018432", eventType: "anomaly", level: "alert", template: utmAnomalyTemplate}}},
+		{typ: "utm", name: "dns", messages: []message{{logId: "1501054802", eventType: "dns-query", level: "information", template: utmDnsTemplate}}},
+		{typ: "event", name: "vpn", messages: []message{{logId: "0101037127", level: "notice", template: eventVpnTemplate}}},
+		{typ: "event", name: "ha", messages: []message{{logId: "0108037893", level: "critical", template: eventHaTemplate}}},
+		{typ: "event", name: "system", messages: []message{
+			{logId: "0100032002", level: "information", template: eventSystemTemplate},
+			{logId: "0100032001", level: "information", template: eventAdminTemplate},
+		}},
+		{typ: "event", name: "user", messages: []message{{logId: "0102043008", level: "notice", template: eventUserTemplate}}},
 	}
 	users          = [...]string{"user01", "user02", "user03", "user04", "user05", "user06", "user07"}
-	levels         = [...]string{"warning", "notice", "information", "error"}
+	admins         = [...]string{"admin", "netops", "secops"}
 	interfaces     = [...]string{"int0", "int1", "int2", "int3", "int4", "int5", "int6", "int7"}
 	roles          = [...]string{"lan", "wan", "internal", "external", "inbound", "outbound"}
 	protocols      = [...]int{6, 17}
@@ -42,75 +106,319 @@ var (
 	queryTypes     = [...]string{"A", "AAAA"}
 	servers        = [...]string{"srv0", "srv1", "srv2", "srv3", "srv4", "srv5", "srv6", "srv7"}
 	trafficActions = [...]string{"deny", "accept"}
+	services       = [...]string{"HTTPS", "SSH", "SNMP", "PING", "DNS"}
+	virusActions   = [...]string{"blocked", "monitored"}
+	ipsActions     = [...]string{"dropped", "detected", "reset"}
+	anomalyActions = [...]string{"clear_session", "detected"}
+	sslReasons     = [...]string{"block-cert-invalid", "block-cert-untrusted", "block-cert-expired", "block-cert-revoked"}
+	vpnTunnels     = [...]string{"to-branch01", "to-branch02", "to-datacenter", "dialup-users"}
+	viruses        = [...]Virus{
+		{Name: "EICAR_TEST_FILE", Id: 2172, File: "eicar.com"},
+		{Name: "W32/Agent.ABCD!tr", Id: 8097631, File: "setup.exe"},
+		{Name: "JS/Miner.BP!tr", Id: 7913234, File: "miner.js"},
+		{Name: "MSOffice/Agent.ACE!tr.dldr", Id: 8285732, File: "invoice.docm"},
+	}
+	categories = [...]Category{
+		{Id: 26, Desc: "Malicious Websites", Action: "blocked", Msg: "URL belongs to a denied category in policy"},
+		{Id: 61, Desc: "Phishing", Action: "blocked", Msg: "URL belongs to a denied category in policy"},
+		{Id: 52, Desc: "Information Technology", Action: "passthrough", Msg: "URL belongs to an allowed category in policy"},
+		{Id: 37, Desc: "Social Networking", Action: "passthrough", Msg: "URL belongs to an allowed category in policy"},
+		{Id: 59, Desc: "Proxy Avoidance", Action: "blocked", Msg: "URL belongs to a denied category in policy"},
+	}
+	attacks = [...]Attack{
+		{Name: "Apache.Log4j.Error.Log.Remote.Code.Execution", Id: 51006, Severity: "critical", Category: "applications3"},
+		{Name: "MS.Windows.SMB.Remote.Code.Execution", Id: 43796, Severity: "critical", Category: "vulnerability"},
+		{Name: "HTTP.URI.SQL.Injection", Id: 15621, Severity: "high", Category: "web_app3"},
+		{Name: "Nmap.Script.Scanner", Id: 43493, Severity: "low", Category: "applications3"},
+	}
+	apps = [...]App{
+		{Name: "Microsoft.Teams", Id: 43541, Category: "Collaboration", Risk: "elevated", Action: "pass"},
+		{Name: "YouTube", Id: 31077, Category: "Video/Audio", Risk: "elevated", Action: "pass"},
+		{Name: "BitTorrent", Id: 16347, Category: "P2P", Risk: "high", Action: "block"},
+		{Name: "Tor", Id: 15013, Category: "Proxy", Risk: "critical", Action: "block"},
+		{Name: "SSL", Id: 15895, Category: "Network.Service", Risk: "elevated", Action: "pass"},
+	}
+	anomalies = [...]Anomaly{
+		{Name: "tcp_syn_flood", Id: 100663396, Threshold: 2000},
+		{Name: "tcp_port_scan", Id: 100663397, Threshold: 1000},
+		{Name: "udp_flood", Id: 100663398, Threshold: 2000},
+		{Name: "icmp_flood", Id: 100663402, Threshold: 250},
+	}
+	vpnEvents = [...]VpnEvent{
+		{Desc: "IPsec connection status changed", Msg: "IPsec connection status change", Action: "tunnel-up", Status: "success", Result: "OK"},
+		{Desc: "IPsec connection status changed", Msg: "IPsec connection status change", Action: "tunnel-down", Status: "success", Result: "OK"},
+		{Desc: "Progress IPsec phase 1", Msg: "progress IPsec phase 1", Action: "negotiate", Status: "success", Result: "DONE"},
+		{Desc: "Progress IPsec phase 1", Msg: "progress IPsec phase 1", Action: "negotiate", Status: "failure", Result: "ERROR"},
+	}
+	haEvents = [...]HaEvent{
+		{Desc: "Virtual cluster's member state moved", Msg: "Virtual cluster's member state moved", Role: "master", Reason: "none"},
+		{Desc: "Heartbeat device interface down", Msg: "Heartbeat device interface down", Role: "slave", Reason: "link down"},
+		{Desc: "HA device interface failed", Msg: "HA device interface failed", Role: "master", Reason: "monitor interface failed"},
+	}
+	adminEvents = [...]AdminEvent{
+		{Desc: "Admin login successful", Msg: "Administrator logged in successfully", Action: "login", Status: "success", Reason: "none"},
+		{Desc: "Admin login failed", Msg: "Administrator login failed from https because of invalid password", Action: "login", Status: "failed", Reason: "passwd_invalid"},
+		{Desc: "Admin logout successful", Msg: "Administrator logged out", Action: "logout", Status: "success", Reason: "exit"},
+	}
+	// severities are the syslog severities of the levels.
+	severities = map[string]int{
+		"emergency":   0,
+		"alert":       1,
+		"critical":    2,
+		"error":       3,
+		"warning":     4,
+		"notice":      5,
+		"information": 6,
+		"debug":       7,
+	}
 )
 
+type subtype struct {
+	typ      string
+	name     string
+	messages []message
+}
+
+type message struct {
+	logId     string
+	eventType string
+	level     string
+	template  string
+}
+
+// compiledMessage is a message of a subtype with its template parsed
+// for the configured version.
+type compiledMessage struct {
+	message
+	typ     string
+	subtype string
+	tmpl    *template.Template
+}
+
+// Virus is a virus found by the antivirus profile.
+type Virus struct {
+	Name string
+	Id   int
+	File string
+}
+
+// Category is a FortiGuard web filter category and what the profile
+// does with it.
+type Category struct {
+	Id     int
+	Desc   string
+	Action string
+	Msg    string
+}
+
+// Attack is an IPS signature.
+type Attack struct {
+	Name     string
+	Id       int
+	Severity string
+	Category string
+}
+
+// App is an application control signature.
+type App struct {
+	Name     string
+	Id       int
+	Category string
+	Risk     string
+	Action   string
+}
+
+// Anomaly is a DoS policy anomaly.
+type Anomaly struct {
+	Name      string
+	Id        int
+	Threshold int
+}
+
+// VpnEvent is an IPsec event.
+type VpnEvent struct {
+	Desc   string
+	Msg    string
+	Action string
+	Status string
+	Result string
+}
+
+// HaEvent is a high availability event.
+type HaEvent struct {
+	Desc   string
+	Msg    string
+	Role   string
+	Reason string
+}
+
+// AdminEvent is an administrator login event.
+type AdminEvent struct {
+	Desc   string
+	Msg    string
+	Action string
+	Status string
+	Reason string
+}
+
 // Firewall holds the random fields for a firewall record
 type Firewall struct {
-	Date           time.Time
-	DevId          string
-	DevName        string
-	Direction      string
-	DstIp          net.IP
-	DstPort        int
-	Duration       int
-	Interface1     string
-	Interface2     string
-	InterfaceRole1 string
-	InterfaceRole2 string
-	Level          string
-	LogId          string
-	PolicyId       int
-	Protocol       int
-	QueryName      string
-	QueryType      string
-	ReceivedBytes  int
-	SentBytes      int
-	SentPackets    int
-	Server         string
-	SessionId      int
-	SrcIp          net.IP
-	SrcPort        int
-	Templates      []*template.Template
-	Timezone       string
-	TrafficAction  string
-	User           string
-	Vd             string
-	XId            int
+	Admin           string
+	AdminEvent      AdminEvent
+	Anomaly         Anomaly
+	AnomalyAction   string
+	App             App
+	Attack          Attack
+	Category        Category
+	Cookies         string
+	Count           int
+	Date            time.Time
+	DevId           string
+	DevName         string
+	Direction       string
+	DstIp           net.IP
+	DstPort         int
+	Duration        int
+	EventType       string
+	HaEvent         HaEvent
+	IncidentSerial  int
+	Interface1      string
+	Interface2      string
+	InterfaceRole1  string
+	InterfaceRole2  string
+	IpsAction       string
+	Level           string
+	LogId           string
+	MulticastIp     net.IP
+	PolicyId        int
+	Protocol        int
+	QueryName       string
+	QueryType       string
+	ReceivedBytes   int
+	ReceivedPackets int
+	SentBytes       int
+	SentPackets     int
+	Server          string
+	Service         string
+	SessionId       int
+	SrcIp           net.IP
+	SrcPort         int
+	SslReason       string
+	Subtype         string
+	Timezone        string
+	TrafficAction   string
+	Type            string
+	User            string
+	UserAgent       string
+	Vd              string
+	Virus           Virus
+	VirusAction     string
+	VpnEvent        VpnEvent
+	VpnTunnel       string
+	XId             int
+
+	subtypes     [][]compiledMessage
+	weighted     *random.Weighted
+	syslogHeader string
+	rnd          *rand.Rand
+	clock        clock.Clock
 }
 
 func init() {
 	generator.Register(Name, New)
+	generator.RegisterInfo(Name, generator.Info{
+		Description: "Fortinet FortiGate traffic, UTM and event logs.",
+		Config:      defaultConfig(),
+	})
 }
 
 // New is the Factory for Firewall objects.
-func New(cfg *ucfg.Config) (generator.Generator, error) {
+func New(cfg *ucfg.Config, r *rand.Rand, clk clock.Clock) (generator.Generator, error) {
 	c := defaultConfig()
 	if err := cfg.Unpack(&c); err != nil {
 		return nil, err
 	}
 
-	f := &Firewall{}
+	f := &Firewall{syslogHeader: c.SyslogHeader, rnd: r, clock: clk}
 	f.randomize()
 
-	for i, v := range msgTemplates {
-		t, err := template.New(strconv.Itoa(i)).Funcs(generator.FunctionMap).Parse(v)
-		if err != nil {
-			return nil, err
+	header := header6
+	if c.Version == Version7 {
+		header = header7
+	}
+	names := c.Subtypes
+	if len(names) == 0 {
+		names = subtypeNames()
+	}
+	weights := c.Weights
+	if len(weights) == 0 {
+		weights = make([]float64, len(names))
+		for i := range weights {
+			weights[i] = 1
 		}
-		f.Templates = append(f.Templates, t)
 	}
+	for _, name := range names {
+		s, _ := findSubtype(name)
+		var messages []compiledMessage
+		for _, m := range s.messages {
+			t, err := template.New(s.name).Funcs(generator.FunctionMap).Parse(header + m.template)
+			if err != nil {
+				return nil, err
+			}
+			messages = append(messages, compiledMessage{message: m, typ: s.typ, subtype: s.name, tmpl: t})
+		}
+		f.subtypes = append(f.subtypes, messages)
+	}
+	w, err := random.NewWeighted(weights)
+	if err != nil {
+		return nil, err
+	}
+	f.weighted = w
 	return f, nil
 }
 
+// subtypeNames returns the names of the supported subtypes.
+func subtypeNames() []string {
+	names := make([]string, len(subtypes))
+	for i, s := range subtypes {
+		names[i] = s.name
+	}
+	return names
+}
+
+// findSubtype returns the subtype name.
+func findSubtype(name string) (subtype, bool) {
+	for _, s := range subtypes {
+		if s.name == name {
+			return s, true
+		}
+	}
+	return subtype{}, false
+}
+
 // Next produces the next firewall record.
 //
 // Example:
 //
-// date=1970-01-02 time=03:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0123456789\" type=\"event\" subtype=\"user\" level=\"error\" vd=\"root\" eventtime=97445 tz=\"-0500\" logdesc=\"FSSO logon authentication status\" srcip=142.155.32.170 user=\"user07\" server=\"srv7\" action=\"FSSO-logon\" msg=\"FSSO-logon event from FSSO_srv7: user user07 logged on 142.155.32.170\"
+// date=1970-01-01 time=22:04:05 devname="testswitch3" devid="testrouter" logid="0102043008" type="event" subtype="user" level="notice" vd="root" eventtime=97445 tz="-0500" logdesc="FSSO logon authentication status" srcip=142.155.32.170 user="user07" server="srv7" action="FSSO-logon" msg="FSSO-logon event from FSSO_srv7: user user07 logged on 142.155.32.170"
 func (f *Firewall) Next() ([]byte, error) {
 	var buf bytes.Buffer
 
-	err := f.Templates[rand.Intn(len(f.Templates))].Execute(&buf, f)
+	s := f.subtypes[f.weighted.Pick(f.rnd)]
+	m := s[f.rnd.Intn(len(s))]
+	f.LogId = m.logId
+	f.Type = m.typ
+	f.Subtype = m.subtype
+	f.EventType = m.eventType
+	f.Level = m.level
+
+	switch f.syslogHeader {
+	case SyslogDefault:
+		fmt.Fprintf(&buf, "<%d>", facility*8+severities[f.Level])
+	case SyslogRFC5424:
+		fmt.Fprintf(&buf, "<%d>1 %s %s - - - - ", facility*8+severities[f.Level], f.Date.Format(time.RFC3339), f.DevName)
+	}
+	err := m.tmpl.Execute(&buf, f)
 	if err != nil {
 		return nil, err
 	}
@@ -123,29 +431,49 @@ func (f *Firewall) Next() ([]byte, error) {
 func (f *Firewall) randomize() {
 	f.DevName = "testswitch3"
 	f.DevId = "testrouter"
-	f.LogId = "0123456789"
-	f.Timezone = "-0500"
-	f.Date = time.Now()
+	f.Date = f.clock.Now().In(timezone)
+	f.Timezone = f.Date.Format("-0700")
 	f.Vd = "root"
-	f.User = users[rand.Intn(len(users))]
-	f.Server = servers[rand.Intn(len(servers))]
-	f.SrcIp = random.IPv4()
-	f.SrcPort = random.Port()
-	f.DstIp = random.IPv4()
-	f.DstPort = random.Port()
-	f.PolicyId = rand.Intn(256)
-	f.SessionId = rand.Intn(65536)
-	f.Interface1 = interfaces[rand.Intn(len(interfaces))]
-	f.Interface2 = interfaces[rand.Intn(len(interfaces))]
-	f.InterfaceRole1 = roles[rand.Intn(len(roles))]
-	f.InterfaceRole2 = roles[rand.Intn(len(roles))]
-	f.Protocol = protocols[rand.Intn(len(protocols))]
-	f.QueryName = queries[rand.Intn(len(queries))]
-	f.QueryType = queryTypes[rand.Intn(len(queryTypes))]
-	f.XId = rand.Intn(256)
-	f.Level = levels[rand.Intn(len(levels))]
-	f.TrafficAction = trafficActions[rand.Intn(len(trafficActions))]
-	f.SentPackets = rand.Intn(65536)
+	f.User = users[f.rnd.Intn(len(users))]
+	f.Server = servers[f.rnd.Intn(len(servers))]
+	f.SrcIp = random.IPv4(f.rnd)
+	f.SrcPort = random.Port(f.rnd)
+	f.DstIp = random.IPv4(f.rnd)
+	f.DstPort = random.Port(f.rnd)
+	f.PolicyId = f.rnd.Intn(256)
+	f.SessionId = f.rnd.Intn(65536)
+	f.Interface1 = interfaces[f.rnd.Intn(len(interfaces))]
+	f.Interface2 = interfaces[f.rnd.Intn(len(interfaces))]
+	f.InterfaceRole1 = roles[f.rnd.Intn(len(roles))]
+	f.InterfaceRole2 = roles[f.rnd.Intn(len(roles))]
+	f.Protocol = protocols[f.rnd.Intn(len(protocols))]
+	f.QueryName = queries[f.rnd.Intn(len(queries))]
+	f.QueryType = queryTypes[f.rnd.Intn(len(queryTypes))]
+	f.XId = f.rnd.Intn(256)
+	f.TrafficAction = trafficActions[f.rnd.Intn(len(trafficActions))]
+	f.SentPackets = f.rnd.Intn(65536)
 	f.SentBytes = f.SentPackets * 1500
-	f.Duration = rand.Intn(1024)
+	f.Duration = f.rnd.Intn(1024)
+	f.ReceivedPackets = f.rnd.Intn(65536)
+	f.ReceivedBytes = f.ReceivedPackets * (64 + f.rnd.Intn(1437))
+	f.Service = services[f.rnd.Intn(len(services))]
+	f.MulticastIp = net.IPv4(239, byte(f.rnd.Intn(256)), byte(f.rnd.Intn(256)), byte(1+f.rnd.Intn(254)))
+	f.UserAgent = random.UserAgent(f.rnd)
+	f.Virus = viruses[f.rnd.Intn(len(viruses))]
+	f.VirusAction = virusActions[f.rnd.Intn(len(virusActions))]
+	f.Category = categories[f.rnd.Intn(len(categories))]
+	f.Attack = attacks[f.rnd.Intn(len(attacks))]
+	f.IpsAction = ipsActions[f.rnd.Intn(len(ipsActions))]
+	f.IncidentSerial = f.rnd.Intn(1 << 30)
+	f.App = apps[f.rnd.Intn(len(apps))]
+	f.SslReason = sslReasons[f.rnd.Intn(len(sslReasons))]
+	f.Anomaly = anomalies[f.rnd.Intn(len(anomalies))]
+	f.AnomalyAction = anomalyActions[f.rnd.Intn(len(anomalyActions))]
+	f.Count = f.Anomaly.Threshold + 1 + f.rnd.Intn(f.Anomaly.Threshold)
+	f.VpnEvent = vpnEvents[f.rnd.Intn(len(vpnEvents))]
+	f.VpnTunnel = vpnTunnels[f.rnd.Intn(len(vpnTunnels))]
+	f.Cookies = fmt.Sprintf("%016x/%016x", f.rnd.Uint64(), f.rnd.Uint64())
+	f.HaEvent = haEvents[f.rnd.Intn(len(haEvents))]
+	f.AdminEvent = adminEvents[f.rnd.Intn(len(adminEvents))]
+	f.Admin = admins[f.rnd.Intn(len(admins))]
 }
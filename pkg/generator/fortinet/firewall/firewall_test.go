@@ -3,39 +3,77 @@ package firewall
 import (
 	"math/rand"
 	"testing"
-	"text/template"
 	"time"
 
-	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestNext(t *testing.T) {
 	tests := map[string]struct {
-		template string
+		c        map[string]interface{}
+		seed     int64
 		expected string
 	}{
-		"EventUser": {template: eventUserTemplate,
-			expected: "date=1970-01-02 time=03:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0123456789\" type=\"event\" subtype=\"user\" level=\"error\" vd=\"root\" eventtime=97445 tz=\"-0500\" logdesc=\"FSSO logon authentication status\" srcip=142.155.32.170 user=\"user07\" server=\"srv7\" action=\"FSSO-logon\" msg=\"FSSO-logon event from FSSO_srv7: user user07 logged on 142.155.32.170\""},
-		"EventSystem": {template: eventSystemTemplate,
-			expected: "date=1970-01-02 time=03:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0123456789\" type=\"event\" subtype=\"system\" level=\"error\" vd=\"root\" eventtime=97445 tz=\"-0500\" logdesc=\"FortiSandbox AV database updated\" version=\"1.522479\" msg=\"FortiSandbox AV database updated\""},
-		"UtmDns": {template: utmDnsTemplate,
-			expected: "date=1970-01-02 time=03:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0123456789\" type=\"utm\" subtype=\"dns\" eventtype=\"dns-query\" level=\"error\" vd=\"root\" eventtime=97445 tz=\"-0500\" policyid=57 sessionid=53932 srcip=142.155.32.170 srcport=1211 srcintf=\"int0\" srcintfrole=\"internal\" dstip=2.11.181.108 dstport=53 dstintf=\"int4\" dstintfrole=\"wan\" proto=6 profile=\"elastictest\" xid=26 qname=\"elastic.co\" qtype=\"A\" qtypeval=1 qclass=\"IN\""},
-		"TrafficForward": {template: trafficForwardTemplate,
-			expected: "date=1970-01-02 time=03:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0123456789\" type=\"traffic\" subtype=\"forward\" level=\"error\" vd=\"root\" eventtime=97445 srcip=142.155.32.170 srcport=1211 srcintf=\"int0\" srcintfrole=\"internal\" dstip=2.11.181.108 dstport=53638 dstintf=\"int4\" dstintfrole=\"wan\" sessionid=53932 proto=6 action=\"accept\" policyid=57 policytype=\"policy\" service=\"SNMP\" dstcountry=\"Reserved\" srccountry=\"Reserved\" trandisp=\"noop\" duration=994 sentbyte=24247500 rcvdbyte=24247500 sentpkt=16165 appcat=\"unscanned\" crscore=30 craction=131072 crlevel=\"high\""},
+		"EventUser": {c: map[string]interface{}{"subtypes": []string{"user"}},
+			expected: "date=1970-01-01 time=22:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0102043008\" type=\"event\" subtype=\"user\" level=\"notice\" vd=\"root\" eventtime=97445 tz=\"-0500\" logdesc=\"FSSO logon authentication status\" srcip=142.155.32.170 user=\"user07\" server=\"srv7\" action=\"FSSO-logon\" msg=\"FSSO-logon event from FSSO_srv7: user user07 logged on 142.155.32.170\""},
+		"EventSystem": {c: map[string]interface{}{"subtypes": []string{"system"}}, seed: 3,
+			expected: "date=1970-01-01 time=22:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0100032002\" type=\"event\" subtype=\"system\" level=\"information\" vd=\"root\" eventtime=97445 tz=\"-0500\" logdesc=\"FortiSandbox AV database updated\" version=\"1.522479\" msg=\"FortiSandbox AV database updated\""},
+		"EventSystemAdmin": {c: map[string]interface{}{"subtypes": []string{"system"}},
+			expected: "date=1970-01-01 time=22:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0100032001\" type=\"event\" subtype=\"system\" level=\"information\" vd=\"root\" eventtime=97445 tz=\"-0500\" logdesc=\"Admin login failed\" sn=\"53932\" user=\"admin\" ui=\"https(142.155.32.170)\" method=\"https\" srcip=142.155.32.170 dstip=2.11.181.108 action=\"login\" status=\"failed\" reason=\"passwd_invalid\" msg=\"Administrator login failed from https because of invalid password\""},
+		"UtmDns": {c: map[string]interface{}{"subtypes": []string{"dns"}},
+			expected: "date=1970-01-01 time=22:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"1501054802\" type=\"utm\" subtype=\"dns\" eventtype=\"dns-query\" level=\"information\" vd=\"root\" eventtime=97445 tz=\"-0500\" policyid=57 sessionid=53932 srcip=142.155.32.170 srcport=1211 srcintf=\"int0\" srcintfrole=\"internal\" dstip=2.11.181.108 dstport=53 dstintf=\"int4\" dstintfrole=\"wan\" proto=6 profile=\"elastictest\" xid=26 qname=\"elastic.co\" qtype=\"A\" qtypeval=1 qclass=\"IN\""},
+		"TrafficForward": {c: map[string]interface{}{"subtypes": []string{"forward"}},
+			expected: "date=1970-01-01 time=22:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0000000013\" type=\"traffic\" subtype=\"forward\" level=\"notice\" vd=\"root\" eventtime=97445 tz=\"-0500\" srcip=142.155.32.170 srcport=1211 srcintf=\"int0\" srcintfrole=\"internal\" dstip=2.11.181.108 dstport=53638 dstintf=\"int4\" dstintfrole=\"wan\" sessionid=53932 proto=6 action=\"accept\" policyid=57 policytype=\"policy\" service=\"SNMP\" dstcountry=\"Reserved\" srccountry=\"Reserved\" trandisp=\"noop\" duration=805 sentbyte=35551500 rcvdbyte=45013170 sentpkt=23701 rcvdpkt=35810 appcat=\"unscanned\" crscore=30 craction=131072 crlevel=\"high\""},
+		"Version7": {c: map[string]interface{}{"subtypes": []string{"forward"}, "version": "7"},
+			expected: "date=1970-01-01 time=22:04:05 devname=\"testswitch3\" devid=\"testrouter\" logver=700140665 eventtime=97445000000000 tz=\"-0500\" logid=\"0000000013\" type=\"traffic\" subtype=\"forward\" level=\"notice\" vd=\"root\" srcip=142.155.32.170 srcport=1211 srcintf=\"int0\" srcintfrole=\"internal\" dstip=2.11.181.108 dstport=53638 dstintf=\"int4\" dstintfrole=\"wan\" sessionid=53932 proto=6 action=\"accept\" policyid=57 policytype=\"policy\" service=\"SNMP\" dstcountry=\"Reserved\" srccountry=\"Reserved\" trandisp=\"noop\" duration=805 sentbyte=35551500 rcvdbyte=45013170 sentpkt=23701 rcvdpkt=35810 appcat=\"unscanned\" crscore=30 craction=131072 crlevel=\"high\""},
+		"SyslogDefault": {c: map[string]interface{}{"subtypes": []string{"ips"}, "syslog_header": "default"},
+			expected: "<185>date=1970-01-01 time=22:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0419016384\" type=\"utm\" subtype=\"ips\" eventtype=\"signature\" level=\"alert\" vd=\"root\" eventtime=97445 tz=\"-0500\" severity=\"low\" srcip=142.155.32.170 srccountry=\"Reserved\" dstip=2.11.181.108 dstcountry=\"Reserved\" srcintf=\"int0\" srcintfrole=\"internal\" dstintf=\"int4\" dstintfrole=\"wan\" sessionid=53932 action=\"detected\" proto=6 service=\"HTTP\" policyid=57 attack=\"Nmap.Script.Scanner\" srcport=1211 dstport=80 direction=\"outgoing\" attackid=43493 profile=\"default\" ref=\"http://www.fortinet.com/ids/VID43493\" incidentserialno=60780408 msg=\"applications3: Nmap.Script.Scanner,\" crscore=50 craction=4096 crlevel=\"critical\""},
+		"SyslogRFC5424": {c: map[string]interface{}{"subtypes": []string{"ha"}, "syslog_header": "rfc5424"},
+			expected: "<186>1 1970-01-01T22:04:05-05:00 testswitch3 - - - - date=1970-01-01 time=22:04:05 devname=\"testswitch3\" devid=\"testrouter\" logid=\"0108037893\" type=\"event\" subtype=\"ha\" level=\"critical\" vd=\"root\" eventtime=97445 tz=\"-0500\" logdesc=\"Heartbeat device interface down\" msg=\"Heartbeat device interface down\" ha_group=0 ha_role=\"slave\" hbdn_reason=\"link down\" sn=\"testrouter\""},
 	}
-	test_time, err := time.Parse(time.RFC3339, "1970-01-02T03:04:05Z")
+	testTime, err := time.Parse(time.RFC3339, "1970-01-02T03:04:05Z")
 	assert.Nil(t, err)
 	for name, tc := range tests {
-		rand.Seed(1)
-		f := &Firewall{}
-		f.randomize()
-		templ, err := template.New(name).Funcs(generator.FunctionMap).Parse(tc.template)
-		assert.Nil(t, err)
-		f.Templates = []*template.Template{templ}
-		f.Date = test_time
+		tc.c["type"] = Name
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		seed := int64(1)
+		if tc.seed != 0 {
+			seed = tc.seed
+		}
+		f, err := New(c, rand.New(rand.NewSource(seed)), clock.Fixed(testTime))
+		assert.Nil(t, err, name)
+		got, err := f.Next()
+		assert.Nil(t, err, name)
+		assert.Equal(t, tc.expected, string(got), name)
+	}
+}
+
+func TestSubtypes(t *testing.T) {
+	for _, s := range subtypes {
+		c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "subtypes": []string{s.name}, "version": "7"})
+		assert.Nil(t, err, s.name)
+		f, err := New(c, rand.New(rand.NewSource(1)), clock.Real)
+		assert.Nil(t, err, s.name)
+		for i := 0; i < 10; i++ {
+			got, err := f.Next()
+			assert.Nil(t, err, s.name)
+			assert.Contains(t, string(got), `type="`+s.typ+`" subtype="`+s.name+`"`, s.name)
+			assert.NotContains(t, string(got), "<no value>", s.name)
+		}
+	}
+}
+
+func TestWeights(t *testing.T) {
+	c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "subtypes": []string{"forward", "vpn"}, "weights": []float64{0, 1}})
+	assert.Nil(t, err)
+	f, err := New(c, rand.New(rand.NewSource(1)), clock.Real)
+	assert.Nil(t, err)
+	for i := 0; i < 100; i++ {
 		got, err := f.Next()
 		assert.Nil(t, err)
-		assert.Equal(t, []byte(tc.expected), got, name)
+		assert.Contains(t, string(got), `type="event" subtype="vpn"`)
 	}
 }
@@ -4,10 +4,12 @@
 package generator
 
 import (
+	"math/rand"
 	"strings"
 	"text/template"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 )
 
 var (
@@ -29,9 +31,12 @@ type config struct {
 }
 
 // New creates a new instance of the generator that is specified by
-// the "type" in the ucfg.Config that is passed in.  If no matching
-// generator is found for that type than an error is returned.
-func New(cfg *ucfg.Config) (Generator, error) {
+// the "type" in the ucfg.Config that is passed in.  All random values
+// of the generator are drawn from r, which must not be shared with
+// generators running concurrently, and all timestamps are read from
+// clk.  If no matching generator is found for that type than an error
+// is returned.
+func New(cfg *ucfg.Config, r *rand.Rand, clk clock.Clock) (Generator, error) {
 	c := config{}
 	err := cfg.Unpack(&c)
 	if err != nil {
@@ -41,5 +46,5 @@ func New(cfg *ucfg.Config) (Generator, error) {
 	if err != nil {
 		return nil, err
 	}
-	return factory(cfg)
+	return factory(cfg, r, clk)
 }
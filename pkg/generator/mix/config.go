@@ -0,0 +1,51 @@
+package mix
+
+import (
+	"fmt"
+
+	"github.com/elastic/go-ucfg"
+)
+
+type config struct {
+	Type       string         `config:"type" validate:"required"`
+	Generators []*ucfg.Config `config:"generators" validate:"required"`
+}
+
+// childConfig holds the settings mix reads from a child generator's
+// config.  The rest of the config belongs to the child itself.
+type childConfig struct {
+	Weight float64 `config:"weight"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type: Name,
+	}
+}
+
+func defaultChildConfig() childConfig {
+	return childConfig{
+		Weight: 1,
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	var total float64
+	for i, g := range c.Generators {
+		cc := defaultChildConfig()
+		if err := g.Unpack(&cc); err != nil {
+			return err
+		}
+		if cc.Weight < 0 {
+			return fmt.Errorf("'%v' is not a valid value for 'generators.%d.weight' expected >= 0", cc.Weight, i)
+		}
+		total += cc.Weight
+	}
+	if total <= 0 {
+		return fmt.Errorf("at least one of 'generators' must have a 'weight' > 0")
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package mix
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/leehinman/spigot/pkg/generator/clf"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		config      map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid": {
+			config: map[string]interface{}{"type": Name, "generators": []interface{}{
+				map[string]interface{}{"type": "clf"},
+				map[string]interface{}{"type": "clf", "weight": 0},
+			}},
+			hasError: false,
+		},
+		"Invalid Type": {
+			config: map[string]interface{}{"type": "Bob", "generators": []interface{}{
+				map[string]interface{}{"type": "clf"},
+			}},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'mix' accessing config",
+		},
+		"No Generators": {
+			config:      map[string]interface{}{"type": Name},
+			hasError:    true,
+			errorString: "missing required field accessing 'generators'",
+		},
+		"Negative Weight": {
+			config: map[string]interface{}{"type": Name, "generators": []interface{}{
+				map[string]interface{}{"type": "clf", "weight": -1},
+			}},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'generators.0.weight' expected >= 0 accessing config",
+		},
+		"Zero Weights": {
+			config: map[string]interface{}{"type": Name, "generators": []interface{}{
+				map[string]interface{}{"type": "clf", "weight": 0},
+			}},
+			hasError:    true,
+			errorString: "at least one of 'generators' must have a 'weight' > 0 accessing config",
+		},
+		"Unknown Child": {
+			config: map[string]interface{}{"type": Name, "generators": []interface{}{
+				map[string]interface{}{"type": "bob"},
+			}},
+			hasError:    true,
+			errorString: "Input bob not registered",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			cfg, err := ucfg.NewFrom(tc.config)
+			assert.NoError(t, err)
+
+			_, err = New(cfg, rand.New(rand.NewSource(1)), clock.Real)
+			if tc.hasError {
+				assert.Error(t, err)
+				assert.Equal(t, tc.errorString, err.Error())
+			}
+			if !tc.hasError {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
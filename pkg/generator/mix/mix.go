@@ -0,0 +1,94 @@
+// Package mix generates an interleaved stream of records from several
+// generators, for example to send a blend of vendors to one syslog
+// output.
+//
+// Configuration:
+//
+//   generators: (list, required) The configs of the generators to mix.
+//               Each may set "weight" (number, optional, default 1).
+//               For every record a generator is picked with
+//               probability proportional to its weight.
+//
+//   - generator:
+//       type: mix
+//       generators:
+//         - type: "cisco:asa"
+//           weight: 5
+//         - type: "fortinet:firewall"
+//           weight: 3
+//         - type: cef
+//           weight: 2
+package mix
+
+import (
+	"math/rand"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "mix"
+
+// Mix holds the child generators and the weighted chooser.
+type Mix struct {
+	generators []generator.Generator
+	weighted   *random.Weighted
+	rnd        *rand.Rand
+}
+
+func init() {
+	generator.Register(Name, New)
+	generator.RegisterInfo(Name, generator.Info{
+		Description: "A weighted blend of records from other generators.",
+		Config:      defaultConfig(),
+		Example: map[string]interface{}{
+			"type": Name,
+			"generators": []interface{}{
+				map[string]interface{}{"type": "aws:vpcflow", "weight": 3},
+				map[string]interface{}{"type": "clf"},
+			},
+		},
+	})
+}
+
+// New returns a new mix generator.  Each child generator gets its own
+// source seeded from r, so the records of one child do not depend on
+// which children are picked.
+func New(cfg *ucfg.Config, r *rand.Rand, clk clock.Clock) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	m := &Mix{rnd: r}
+	weights := make([]float64, len(c.Generators))
+	for i, gc := range c.Generators {
+		cc := defaultChildConfig()
+		if err := gc.Unpack(&cc); err != nil {
+			return nil, err
+		}
+		weights[i] = cc.Weight
+
+		g, err := generator.New(gc, rand.New(rand.NewSource(r.Int63())), clk)
+		if err != nil {
+			return nil, err
+		}
+		m.generators = append(m.generators, g)
+	}
+
+	w, err := random.NewWeighted(weights)
+	if err != nil {
+		return nil, err
+	}
+	m.weighted = w
+
+	return m, nil
+}
+
+// Next produces the next record of a randomly picked child generator.
+func (m *Mix) Next() ([]byte, error) {
+	return m.generators[m.weighted.Pick(m.rnd)].Next()
+}
@@ -0,0 +1,57 @@
+package mix
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	tests := map[string]struct {
+		common   float64
+		combined float64
+		want     float64
+	}{
+		"Only Common": {
+			common:   1,
+			combined: 0,
+			want:     0,
+		},
+		"Only Combined": {
+			common:   0,
+			combined: 2,
+			want:     1,
+		},
+		"Blend": {
+			common:   1,
+			combined: 3,
+			want:     0.75,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			cfg := ucfg.MustNewFrom(map[string]interface{}{"type": Name, "generators": []interface{}{
+				map[string]interface{}{"type": "clf", "weight": tc.common},
+				map[string]interface{}{"type": "clf", "combined": true, "weight": tc.combined},
+			}})
+			g, err := New(cfg, rand.New(rand.NewSource(1)), clock.Real)
+			assert.NoError(t, err)
+
+			// Only combined records end with the quoted user agent.
+			n, combined := 4000, 0
+			for i := 0; i < n; i++ {
+				b, err := g.Next()
+				assert.NoError(t, err)
+				if bytes.HasSuffix(b, []byte(`"`)) {
+					combined++
+				}
+			}
+			assert.InDelta(t, tc.want, float64(combined)/float64(n), 0.03)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package panos
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Type      string `config:"type" validate:"required"`
+	EventType string `config:"event_type"`
+	Version   string `config:"version"`
+}
+
+func defaultConfig() config {
+	return config{
+		Type:    Name,
+		Version: "10.2",
+	}
+}
+
+func (c *config) Validate() error {
+	if c.Type != Name {
+		return fmt.Errorf("'%s' is not a valid value for 'type' expected '%s'", c.Type, Name)
+	}
+	version := normalizeVersion(c.Version)
+	if versionIndex(version) < 0 {
+		return fmt.Errorf("'%s' is not a valid value for 'version' expected one of %s", version, strings.Join(versions, ", "))
+	}
+	if c.EventType == "" {
+		return nil
+	}
+	for _, l := range logTypes {
+		if l.name != c.EventType {
+			continue
+		}
+		if versionIndex(version) < versionIndex(l.since) {
+			return fmt.Errorf("'%s' logs require 'version' %s or later", c.EventType, l.since)
+		}
+		return nil
+	}
+	names := make([]string, len(logTypes))
+	for i, l := range logTypes {
+		names[i] = l.name
+	}
+	return fmt.Errorf("'%s' is not a valid value for 'event_type' expected one of %s", c.EventType, strings.Join(names, ", "))
+}
+
+// normalizeVersion returns v with a minor version, an unquoted 10.0 in
+// YAML is the number 10.
+func normalizeVersion(v string) string {
+	if !strings.Contains(v, ".") {
+		return v + ".0"
+	}
+	return v
+}
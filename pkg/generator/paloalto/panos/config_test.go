@@ -0,0 +1,85 @@
+package panos
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Type": {
+			c:           map[string]interface{}{"type": Name},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "Bob"},
+			hasError:    true,
+			errorString: "'Bob' is not a valid value for 'type' expected 'paloalto:panos' accessing config",
+		},
+		"No Type": {
+			c:           map[string]interface{}{"type": ""},
+			hasError:    true,
+			errorString: "string value is not set accessing 'type'",
+		},
+		"Valid Event Type": {
+			c:           map[string]interface{}{"type": Name, "event_type": "threat"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Event Type": {
+			c:           map[string]interface{}{"type": Name, "event_type": "hip-match"},
+			hasError:    true,
+			errorString: "'hip-match' is not a valid value for 'event_type' expected one of traffic, threat, system, config, globalprotect accessing config",
+		},
+		"Valid Version": {
+			c:           map[string]interface{}{"type": Name, "version": "9.0"},
+			hasError:    false,
+			errorString: "",
+		},
+		"Major Version": {
+			c:           map[string]interface{}{"type": Name, "version": 10},
+			hasError:    false,
+			errorString: "",
+		},
+		"Invalid Version": {
+			c:           map[string]interface{}{"type": Name, "version": "7.1"},
+			hasError:    true,
+			errorString: "'7.1' is not a valid value for 'version' expected one of 8.1, 9.0, 9.1, 10.0, 10.1, 10.2 accessing config",
+		},
+		"GlobalProtect Too Old": {
+			c:           map[string]interface{}{"type": Name, "event_type": "globalprotect", "version": "9.0"},
+			hasError:    true,
+			errorString: "'globalprotect' logs require 'version' 9.1 or later accessing config",
+		},
+	}
+	for name, tc := range tests {
+		c, err := ucfg.NewFrom(tc.c)
+		assert.Nil(t, err, name)
+		_, err = New(c, rand.New(rand.NewSource(1)), clock.Real)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, err.Error(), tc.errorString, name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
+
+func TestValidateVersion(t *testing.T) {
+	// Validate is also run by "spigot validate" and must not change
+	// the config it checks.
+	c := defaultConfig()
+	c.Version = "10"
+	assert.Nil(t, c.Validate())
+	assert.Equal(t, "10", c.Version)
+}
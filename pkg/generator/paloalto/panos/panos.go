@@ -0,0 +1,606 @@
+// Package panos generates Palo Alto Networks PAN-OS firewall logs in
+// the comma separated format PAN-OS uses for syslog.  TRAFFIC,
+// THREAT, SYSTEM, CONFIG and GLOBALPROTECT logs are supported.  Every
+// PAN-OS release appends fields to the end of its logs, so the
+// fields written depend on the version.
+//
+// Configuration:
+//
+//	event_type: The type of log to generate, or leave blank for random.
+//	            Valid values are: traffic, threat, system, config, globalprotect.
+//	            globalprotect logs require version 9.1 or later.
+//	version:    The PAN-OS version whose log format is written.  Valid
+//	            values are 8.1, 9.0, 9.1, 10.0, 10.1 and 10.2.  Default 10.2.
+//
+//	- generator:
+//	    type: "paloalto:panos"
+//	    event_type: threat
+//	    version: "10.1"
+package panos
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/google/uuid"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/leehinman/spigot/pkg/generator"
+	"github.com/leehinman/spigot/pkg/random"
+)
+
+// Name is the name used in the configuration file and the registry.
+const Name = "paloalto:panos"
+
+const (
+	EventTypeTraffic       = "traffic"
+	EventTypeThreat        = "threat"
+	EventTypeSystem        = "system"
+	EventTypeConfig        = "config"
+	EventTypeGlobalProtect = "globalprotect"
+)
+
+// versions are the PAN-OS versions whose log formats are known, oldest
+// first.
+var versions = []string{"8.1", "9.0", "9.1", "10.0", "10.1", "10.2"}
+
+func versionIndex(v string) int {
+	for i, s := range versions {
+		if s == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// fields are the fields a PAN-OS version added to the end of a log,
+// as a template of comma separated values.
+type fields struct {
+	since  string
+	format string
+}
+
+// logType is a type of log with the fields it has in each version.
+type logType struct {
+	name      string
+	since     string
+	fields    []fields
+	randomize func(*Panos)
+}
+
+var logTypes = []logType{
+	{
+		name:      EventTypeTraffic,
+		since:     "8.1",
+		randomize: (*Panos).randomizeTraffic,
+		fields: []fields{
+			{"8.1", "1,{{ts .ReceiveTime}},{{.Serial}},TRAFFIC,{{.Subtype}},2049,{{ts .GeneratedTime}},{{.SrcIp}},{{.DstIp}},{{.NatSrcIp}},{{.NatDstIp}},{{.Rule}},{{.SrcUser}},,{{.App.Name}},{{.Vsys}},{{.SrcZone}},{{.DstZone}},{{.InInterface}},{{.OutInterface}},default,{{ts .GeneratedTime}},{{.SessionId}},1,{{.SrcPort}},{{.DstPort}},{{.NatSrcPort}},{{.NatDstPort}},{{.Flags}},{{.App.Protocol}},{{.Action}},{{.Bytes}},{{.BytesSent}},{{.BytesReceived}},{{.Packets}},{{ts .StartTime}},{{.Elapsed}},{{.Category}},0,{{.Sequence}},0x8000000000000000,{{.SrcLocation}},{{.DstLocation}},0,{{.PacketsSent}},{{.PacketsReceived}},{{.SessionEndReason}},0,0,0,0,,{{.DeviceName}},from-policy,,,0,,0,,N/A,0,0,0,0"},
+			{"9.0", "{{.RuleUuid}},0"},
+			{"9.1", "0,,0,,,,,"},
+			{"10.0", ",,,,,,,,,,,,,,,,,,,,,,,,,,0,{{hrts .GeneratedTime}},,"},
+			{"10.1", "{{.App.Subcategory}},{{.App.Category}},{{.App.Technology}},{{.App.Risk}},,{{.App.Name}},untunneled,no,no,0"},
+			{"10.2", "NonProxyTraffic,"},
+		},
+	},
+	{
+		name:      EventTypeThreat,
+		since:     "8.1",
+		randomize: (*Panos).randomizeThreat,
+		fields: []fields{
+			{"8.1", "1,{{ts .ReceiveTime}},{{.Serial}},THREAT,{{.Subtype}},2049,{{ts .GeneratedTime}},{{.SrcIp}},{{.DstIp}},{{.NatSrcIp}},{{.NatDstIp}},{{.Rule}},{{.SrcUser}},,{{.App.Name}},{{.Vsys}},{{.SrcZone}},{{.DstZone}},{{.InInterface}},{{.OutInterface}},default,{{ts .GeneratedTime}},{{.SessionId}},1,{{.SrcPort}},{{.DstPort}},{{.NatSrcPort}},{{.NatDstPort}},{{.Flags}},{{.App.Protocol}},{{.Action}},{{quote .Misc}},{{.Threat.Name}}({{.Threat.Id}}),{{.Category}},{{.Threat.Severity}},{{.Direction}},{{.Sequence}},0x8000000000000000,{{.SrcLocation}},{{.DstLocation}},0,,0,{{.FileDigest}},{{.Cloud}},0,{{quote .UserAgent}},{{.FileType}},,,,,,{{.ReportId}},0,0,0,0,,{{.DeviceName}},,,,{{.HttpMethod}},0,,0,,N/A,{{.Threat.Category}},{{.ContentVersion}},0,0,0,"},
+			{"9.0", "{{quote .UrlCategoryList}},{{.RuleUuid}},0"},
+			{"9.1", ""},
+			{"10.0", ",,,,,,,,,,,,,,,,,,,,,,,,,,,0,{{hrts .GeneratedTime}},,,"},
+			{"10.1", "{{.App.Subcategory}},{{.App.Category}},{{.App.Technology}},{{.App.Risk}},,{{.App.Name}},untunneled,no,no,"},
+			{"10.2", ",NonProxyTraffic"},
+		},
+	},
+	{
+		name:      EventTypeSystem,
+		since:     "8.1",
+		randomize: (*Panos).randomizeSystem,
+		fields: []fields{
+			{"8.1", "1,{{ts .ReceiveTime}},{{.Serial}},SYSTEM,{{.Subtype}},0,{{ts .GeneratedTime}},{{.Vsys}},{{.System.EventId}},{{.System.Object}},0,0,{{.System.Module}},{{.System.Severity}},{{quote .System.Description}},{{.Sequence}},0x0,0,0,0,0,,{{.DeviceName}}"},
+			{"10.0", "0,0,{{hrts .GeneratedTime}}"},
+		},
+	},
+	{
+		name:      EventTypeConfig,
+		since:     "8.1",
+		randomize: (*Panos).randomizeConfig,
+		fields: []fields{
+			{"8.1", "1,{{ts .ReceiveTime}},{{.Serial}},CONFIG,0,0,{{ts .GeneratedTime}},{{.SrcIp}},{{.Vsys}},{{.Config.Command}},{{.SrcUser}},{{.Config.Client}},{{.Config.Result}},{{quote .Config.Path}},{{quote .Config.Before}},{{quote .Config.After}},{{.Sequence}},0x0,0,0,0,0,,{{.DeviceName}}"},
+			{"10.0", ",,0,{{hrts .GeneratedTime}}"},
+		},
+	},
+	{
+		name:      EventTypeGlobalProtect,
+		since:     "9.1",
+		randomize: (*Panos).randomizeGlobalProtect,
+		fields: []fields{
+			{"9.1", "1,{{ts .ReceiveTime}},{{.Serial}},GLOBALPROTECT,0,2305,{{ts .GeneratedTime}},{{.Vsys}},{{.GlobalProtect.EventId}},{{.GlobalProtect.Stage}},{{.AuthMethod}},{{.TunnelType}},{{.SrcUser}},{{.DstLocation}},{{.MachineName}},{{.DstIp}},,{{.SrcIp}},,{{.HostId}},{{.ClientSerial}},{{.ClientVersion}},{{.ClientOs}},{{quote .ClientOsVersion}},1,,{{quote .GlobalProtect.Error}},{{quote .GlobalProtect.Description}},{{.GlobalProtect.Status}},,{{.Elapsed}},{{.ConnectMethod}},{{.GlobalProtect.ErrorCode}},{{.Portal}},{{.Sequence}},0x8000000000000000,{{hrts .GeneratedTime}},{{.SelectionType}},{{.ResponseTime}},{{.Priority}},,{{.Gateway}},0,0,0,0,,{{.DeviceName}},1"},
+		},
+	},
+}
+
+// funcs are the template functions used by the fields.
+var funcs = template.FuncMap{
+	"ts":    func(t time.Time) string { return t.Format("2006/01/02 15:04:05") },
+	"hrts":  func(t time.Time) string { return t.Format("2006-01-02T15:04:05.000-07:00") },
+	"quote": quote,
+}
+
+// quote quotes a free text field, as PAN-OS does, so that commas in it
+// do not split it.
+func quote(s string) string {
+	if s == "" {
+		return ""
+	}
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+// application is an App-ID with the metadata PAN-OS logs for it.
+type application struct {
+	Name        string
+	Port        int
+	Protocol    string
+	Subcategory string
+	Category    string
+	Technology  string
+	Risk        int
+	Web         bool
+}
+
+// threat is a threat signature.  Subtype is the THREAT log subtype it
+// is reported under.
+type threat struct {
+	Subtype  string
+	Name     string
+	Id       int
+	Category string
+	Severity string
+	Actions  []string
+}
+
+type systemEvent struct {
+	Subtype     string
+	EventId     string
+	Object      string
+	Module      string
+	Severity    string
+	Description string
+}
+
+type configChange struct {
+	Command string
+	Client  string
+	Result  string
+	Path    string
+	Before  string
+	After   string
+}
+
+type globalProtectEvent struct {
+	EventId     string
+	Stage       string
+	Status      string
+	Error       string
+	ErrorCode   int
+	Description string
+}
+
+type trafficSubtype struct {
+	subtype string
+	action  string
+	reasons []string
+}
+
+var (
+	applications = [...]application{
+		{Name: "ssl", Port: 443, Protocol: "tcp", Subcategory: "encrypted-tunnel", Category: "networking", Technology: "browser-based", Risk: 4, Web: true},
+		{Name: "web-browsing", Port: 80, Protocol: "tcp", Subcategory: "internet-utility", Category: "general-internet", Technology: "browser-based", Risk: 4, Web: true},
+		{Name: "google-base", Port: 443, Protocol: "tcp", Subcategory: "internet-utility", Category: "general-internet", Technology: "browser-based", Risk: 4, Web: true},
+		{Name: "ms-office365-base", Port: 443, Protocol: "tcp", Subcategory: "office-programs", Category: "business-systems", Technology: "browser-based", Risk: 2, Web: true},
+		{Name: "dns", Port: 53, Protocol: "udp", Subcategory: "infrastructure", Category: "networking", Technology: "network-protocol", Risk: 3},
+		{Name: "ntp", Port: 123, Protocol: "udp", Subcategory: "infrastructure", Category: "networking", Technology: "network-protocol", Risk: 2},
+		{Name: "ssh", Port: 22, Protocol: "tcp", Subcategory: "encrypted-tunnel", Category: "networking", Technology: "client-server", Risk: 4},
+		{Name: "ms-rdp", Port: 3389, Protocol: "tcp", Subcategory: "remote-access", Category: "networking", Technology: "client-server", Risk: 4},
+		{Name: "smtp", Port: 25, Protocol: "tcp", Subcategory: "email", Category: "collaboration", Technology: "client-server", Risk: 5},
+		{Name: "msrpc", Port: 135, Protocol: "tcp", Subcategory: "infrastructure", Category: "networking", Technology: "client-server", Risk: 3},
+	}
+	trafficSubtypes = [...]trafficSubtype{
+		{subtype: "start", action: "allow", reasons: []string{"n/a"}},
+		{subtype: "end", action: "allow", reasons: []string{"aged-out", "tcp-fin", "tcp-rst-from-client", "tcp-rst-from-server"}},
+		{subtype: "drop", action: "drop", reasons: []string{"policy-deny"}},
+		{subtype: "deny", action: "deny", reasons: []string{"policy-deny"}},
+	}
+	threats = [...]threat{
+		{Subtype: "url", Name: "", Id: 9999, Severity: "informational", Actions: []string{"alert", "block-url"}},
+		{Subtype: "virus", Name: "Virus/Win32.WGeneric.eqxrkp", Id: 372638424, Category: "virus", Severity: "medium", Actions: []string{"alert", "reset-both"}},
+		{Subtype: "spyware", Name: "Cobalt Strike Beacon Command and Control Traffic Detection", Id: 86246, Category: "spyware", Severity: "critical", Actions: []string{"alert", "reset-both", "drop"}},
+		{Subtype: "spyware", Name: "Suspicious DNS Query (generic:malicious.example)", Id: 109010001, Category: "dns-c2", Severity: "medium", Actions: []string{"sinkhole", "alert"}},
+		{Subtype: "vulnerability", Name: "Apache Log4j Remote Code Execution Vulnerability", Id: 91991, Category: "code-execution", Severity: "critical", Actions: []string{"alert", "reset-both"}},
+		{Subtype: "vulnerability", Name: "Microsoft Windows SMB Remote Code Execution Vulnerability", Id: 41388, Category: "code-execution", Severity: "critical", Actions: []string{"alert", "reset-server"}},
+		{Subtype: "vulnerability", Name: "HTTP Directory Traversal Vulnerability", Id: 30844, Category: "info-leak", Severity: "medium", Actions: []string{"alert", "reset-both"}},
+		{Subtype: "file", Name: "Windows Executable (EXE)", Id: 52020, Category: "file", Severity: "low", Actions: []string{"alert", "block"}},
+		{Subtype: "file", Name: "PDF File", Id: 52016, Category: "file", Severity: "informational", Actions: []string{"alert"}},
+		{Subtype: "wildfire", Name: "Windows Executable (EXE)", Id: 52020, Category: "malware", Severity: "high", Actions: []string{"alert", "allow"}},
+	}
+	systemEvents = [...]systemEvent{
+		{Subtype: "general", EventId: "general", Module: "general", Severity: "informational", Description: "User admin logged in via Web from 10.0.0.5 using https"},
+		{Subtype: "general", EventId: "general", Module: "general", Severity: "informational", Description: "Connection to Update server: updates.paloaltonetworks.com completed successfully, initiated by 10.0.0.1"},
+		{Subtype: "auth", EventId: "auth-success", Object: "GP-Auth", Module: "general", Severity: "informational", Description: "When authenticating user 'jdoe' from '10.0.1.25'. Authenticated user 'jdoe' via Authentication Profile 'GP-Auth', From: 10.0.1.25."},
+		{Subtype: "auth", EventId: "auth-fail", Object: "GP-Auth", Module: "general", Severity: "medium", Description: "failed authentication for user 'asmith'. Reason: Invalid username/password. From: 203.0.113.7."},
+		{Subtype: "ha", EventId: "state-change", Module: "ha", Severity: "critical", Description: "HA Group 1: Moved from state Passive to state Active"},
+		{Subtype: "vpn", EventId: "ike-nego-p1-succ", Object: "branch-tunnel", Module: "vpn", Severity: "informational", Description: "IKE phase-1 negotiation is succeeded as initiator, main mode. Established SA: 198.51.100.1[500]-203.0.113.1[500] cookie:7a8b2e34f1c0d9e5:9c3d4e5f6a7b8c9d lifetime 28800 Sec."},
+		{Subtype: "vpn", EventId: "ike-nego-p2-fail", Object: "branch-tunnel", Module: "vpn", Severity: "high", Description: "IKE phase-2 negotiation is failed as initiator, quick mode. Failed SA: 198.51.100.1[500]-203.0.113.1[500] message id:0x2F4A61B3."},
+		{Subtype: "routing", EventId: "routed-bgp-peer-status", Object: "default", Module: "routing", Severity: "informational", Description: "BGP peer session established with 192.0.2.1"},
+		{Subtype: "dhcp", EventId: "lease-start", Module: "dhcp", Severity: "informational", Description: "DHCP lease started ip 10.0.1.25 --> mac 00:50:56:a1:b2:c3 - hostname WKS-0142, interface ethernet1/2"},
+		{Subtype: "general", EventId: "upgrade-url-database", Module: "general", Severity: "informational", Description: "PAN-DB was upgraded to version 20240815.20155."},
+	}
+	configChanges = [...]configChange{
+		{Command: "set", Client: "Web", Result: "Succeeded", Path: "vsys  vsys1 rulebase security rules  allow-web", After: "allow-web { from trust; to untrust; source any; destination any; application [ ssl web-browsing ]; service application-default; action allow; }"},
+		{Command: "edit", Client: "Web", Result: "Succeeded", Path: "vsys  vsys1 address  web-server", Before: "web-server { ip-netmask 10.0.2.10; }", After: "web-server { ip-netmask 10.0.2.11; }"},
+		{Command: "delete", Client: "CLI", Result: "Succeeded", Path: "vsys  vsys1 rulebase security rules  temp-allow", Before: "temp-allow { from any; to any; action allow; }"},
+		{Command: "set", Client: "CLI", Result: "Failed", Path: "deviceconfig system  ntp-servers"},
+		{Command: "commit", Client: "Web", Result: "Submitted"},
+		{Command: "commit", Client: "Web", Result: "Succeeded"},
+		{Command: "rename", Client: "Web", Result: "Succeeded", Path: "vsys  vsys1 rulebase security rules  allow-dns"},
+	}
+	globalProtectEvents = [...]globalProtectEvent{
+		{EventId: "portal-prelogin", Stage: "before-login", Status: "success"},
+		{EventId: "portal-auth", Stage: "login", Status: "success"},
+		{EventId: "portal-auth", Stage: "login", Status: "failure", Error: "Authentication failed: Invalid username or password", ErrorCode: 1},
+		{EventId: "portal-getconfig", Stage: "configuration", Status: "success", Description: "Config name: GP-Portal-Config, Config version: 3"},
+		{EventId: "gateway-auth", Stage: "login", Status: "success"},
+		{EventId: "gateway-register", Stage: "tunnel", Status: "success"},
+		{EventId: "gateway-connected", Stage: "connected", Status: "success"},
+		{EventId: "gateway-logout", Stage: "logout", Status: "success", Description: "client logout"},
+	}
+	urlCategories = [...]string{"computer-and-internet-info", "business-and-economy", "search-engines", "social-networking", "news", "streaming-media", "web-advertisements"}
+	hosts         = [...]string{"www.example.com", "cdn.example.net", "login.example.org", "api.example.io", "updates.example.com"}
+	files         = [...]string{"setup.exe", "invoice.pdf", "update.exe", "report.docx", "installer.msi"}
+	users         = [...]string{`acme\jdoe`, `acme\asmith`, `acme\bwilson`, `acme\mgarcia`, `acme\kchen`, `acme\svc-backup`}
+	admins        = [...]string{"admin", "netops", "secops"}
+	rules         = [...]string{"allow-web", "allow-dns", "allow-outbound", "allow-rdp-jump", "block-malicious", "interzone-default", "intrazone-default"}
+	countries     = [...]string{"US", "DE", "GB", "NL", "JP", "CN", "RU", "BR", "IN", "FR"}
+	clientOses    = [...]struct{ os, version string }{
+		{"Windows", "Microsoft Windows 10 Enterprise , 64-bit"},
+		{"Windows", "Microsoft Windows 11 Pro , 64-bit"},
+		{"Mac", "Apple Mac OS X 14.5.0"},
+		{"Linux", "Linux Ubuntu 22.04"},
+	}
+	clientVersions = [...]string{"5.2.13-6", "6.0.7-13", "6.1.3-12", "6.2.1-22"}
+	connectMethods = [...]string{"on-demand", "user-logon", "pre-logon"}
+	authMethods    = [...]string{"LDAP", "SAML", "RADIUS"}
+	tunnelTypes    = [...]string{"IPSec", "SSL"}
+	selectionTypes = [...]string{"preferred", "automatic", "manual"}
+	priorities     = [...]string{"Highest", "High", "Medium", "Low"}
+)
+
+// Panos holds the random fields for a PAN-OS log record.
+type Panos struct {
+	ReceiveTime      time.Time
+	GeneratedTime    time.Time
+	StartTime        time.Time
+	Serial           string
+	DeviceName       string
+	Vsys             string
+	Subtype          string
+	Sequence         int64
+	SrcIp            net.IP
+	DstIp            net.IP
+	NatSrcIp         net.IP
+	NatDstIp         net.IP
+	SrcPort          int
+	DstPort          int
+	NatSrcPort       int
+	NatDstPort       int
+	SrcUser          string
+	SrcZone          string
+	DstZone          string
+	InInterface      string
+	OutInterface     string
+	SrcLocation      string
+	DstLocation      string
+	Rule             string
+	RuleUuid         string
+	App              application
+	SessionId        int
+	Flags            string
+	Action           string
+	Category         string
+	Bytes            int
+	BytesSent        int
+	BytesReceived    int
+	Packets          int
+	PacketsSent      int
+	PacketsReceived  int
+	Elapsed          int
+	SessionEndReason string
+	Threat           threat
+	Misc             string
+	Direction        string
+	UrlCategoryList  string
+	UserAgent        string
+	HttpMethod       string
+	FileType         string
+	FileDigest       string
+	Cloud            string
+	ReportId         int64
+	ContentVersion   string
+	System           systemEvent
+	Config           configChange
+	GlobalProtect    globalProtectEvent
+	AuthMethod       string
+	TunnelType       string
+	MachineName      string
+	HostId           string
+	ClientSerial     string
+	ClientVersion    string
+	ClientOs         string
+	ClientOsVersion  string
+	ConnectMethod    string
+	Portal           string
+	Gateway          string
+	SelectionType    string
+	ResponseTime     int
+	Priority         string
+
+	types     []compiledType
+	ruleUuids map[string]string
+	publicIp  net.IP
+	rnd       *rand.Rand
+	clock     clock.Clock
+}
+
+// compiledType is a logType with its fields for the configured
+// version.
+type compiledType struct {
+	template  *template.Template
+	randomize func(*Panos)
+}
+
+func init() {
+	generator.Register(Name, New)
+	generator.RegisterInfo(Name, generator.Info{
+		Description: "Palo Alto Networks PAN-OS traffic, threat, system, config and GlobalProtect logs.",
+		Config:      defaultConfig(),
+	})
+}
+
+// New is the Factory for Panos objects.
+func New(cfg *ucfg.Config, r *rand.Rand, clk clock.Clock) (generator.Generator, error) {
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+
+	p := &Panos{
+		Serial:     fmt.Sprintf("0%011d", r.Int63n(1e11)),
+		DeviceName: fmt.Sprintf("PA-%d", 3200+r.Intn(8)*10),
+		Sequence:   r.Int63n(1 << 32),
+		ruleUuids:  make(map[string]string, len(rules)),
+		publicIp:   random.IPv4(r),
+		rnd:        r,
+		clock:      clk,
+	}
+	for _, rule := range rules {
+		id, err := uuid.NewRandomFromReader(r)
+		if err != nil {
+			return nil, err
+		}
+		p.ruleUuids[rule] = id.String()
+	}
+
+	version := versionIndex(normalizeVersion(c.Version))
+	for _, l := range logTypes {
+		if c.EventType != "" && c.EventType != l.name {
+			continue
+		}
+		if version < versionIndex(l.since) {
+			continue
+		}
+		var format []string
+		for _, f := range l.fields {
+			if versionIndex(f.since) <= version {
+				format = append(format, f.format)
+			}
+		}
+		t, err := template.New(l.name).Funcs(generator.FunctionMap).Funcs(funcs).Parse(strings.Join(format, ","))
+		if err != nil {
+			return nil, err
+		}
+		p.types = append(p.types, compiledType{template: t, randomize: l.randomize})
+	}
+	return p, nil
+}
+
+// Next produces the next PAN-OS log record.
+//
+// Example:
+//
+// 1,2024/08/15 10:23:45,012801096514,TRAFFIC,end,2049,2024/08/15 10:23:45,10.0.1.25,93.184.216.34,198.51.100.1,0.0.0.0,allow-web,acme\jdoe,,ssl,vsys1,trust,untrust,ethernet1/2,ethernet1/1,default,2024/08/15 10:23:45,482913,1,53422,443,21877,0,0x400019,tcp,allow,18822,2214,16608,31,2024/08/15 10:23:13,32,computer-and-internet-info,0,3344556677,0x8000000000000000,10.0.0.0-10.255.255.255,US,0,12,19,tcp-fin,0,0,0,0,,PA-3220,from-policy,,,0,,0,,N/A,0,0,0,0,...
+func (p *Panos) Next() ([]byte, error) {
+	var buf bytes.Buffer
+
+	t := p.types[p.rnd.Intn(len(p.types))]
+	p.randomize()
+	t.randomize(p)
+
+	if err := t.template.Execute(&buf, p); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// randomize sets the fields shared by all log types.
+func (p *Panos) randomize() {
+	now := p.clock.Now()
+	p.ReceiveTime = now
+	p.GeneratedTime = now
+	p.Sequence++
+	p.Vsys = "vsys1"
+	p.SrcIp = net.IPv4(10, byte(p.rnd.Intn(4)), byte(p.rnd.Intn(256)), byte(1+p.rnd.Intn(254)))
+	p.SrcLocation = "10.0.0.0-10.255.255.255"
+	p.SrcUser = users[p.rnd.Intn(len(users))]
+	p.DstIp = random.IPv4(p.rnd)
+	p.DstLocation = countries[p.rnd.Intn(len(countries))]
+}
+
+// randomizeSession sets the fields of the session that TRAFFIC and
+// THREAT logs report on.
+func (p *Panos) randomizeSession() {
+	p.App = applications[p.rnd.Intn(len(applications))]
+	p.Rule = rules[p.rnd.Intn(len(rules))]
+	p.RuleUuid = p.ruleUuids[p.Rule]
+	p.SrcZone = "trust"
+	p.DstZone = "untrust"
+	p.InInterface = "ethernet1/2"
+	p.OutInterface = "ethernet1/1"
+	p.NatSrcIp = p.publicIp
+	p.NatDstIp = net.IPv4zero
+	p.SrcPort = 1024 + p.rnd.Intn(64512)
+	p.DstPort = p.App.Port
+	p.NatSrcPort = 1024 + p.rnd.Intn(64512)
+	p.NatDstPort = 0
+	p.Flags = "0x400019"
+	p.SessionId = p.rnd.Intn(1 << 20)
+	p.Category = "any"
+	if p.App.Web {
+		p.Category = urlCategories[p.rnd.Intn(len(urlCategories))]
+	}
+}
+
+func (p *Panos) randomizeTraffic() {
+	p.randomizeSession()
+	s := trafficSubtypes[p.rnd.Intn(len(trafficSubtypes))]
+	p.Subtype = s.subtype
+	p.Action = s.action
+	p.SessionEndReason = s.reasons[p.rnd.Intn(len(s.reasons))]
+
+	p.PacketsSent = 1 + p.rnd.Intn(64)
+	p.PacketsReceived = 0
+	p.Elapsed = 0
+	switch s.subtype {
+	case "start":
+		p.PacketsReceived = p.rnd.Intn(4)
+	case "end":
+		p.PacketsReceived = p.rnd.Intn(1024)
+		p.Elapsed = p.rnd.Intn(3600)
+	default:
+		p.PacketsSent = 1
+		p.Flags = "0x0"
+		p.NatSrcIp = net.IPv4zero
+		p.NatSrcPort = 0
+	}
+	p.BytesSent = p.PacketsSent * (60 + p.rnd.Intn(1400))
+	p.BytesReceived = p.PacketsReceived * (60 + p.rnd.Intn(1400))
+	p.Packets = p.PacketsSent + p.PacketsReceived
+	p.Bytes = p.BytesSent + p.BytesReceived
+	p.StartTime = p.GeneratedTime.Add(-time.Duration(p.Elapsed) * time.Second)
+}
+
+func (p *Panos) randomizeThreat() {
+	p.randomizeSession()
+	p.Threat = threats[p.rnd.Intn(len(threats))]
+	p.Subtype = p.Threat.Subtype
+	p.Action = p.Threat.Actions[p.rnd.Intn(len(p.Threat.Actions))]
+	p.Direction = "client-to-server"
+	p.ContentVersion = fmt.Sprintf("AppThreat-%d-%d", 8700+p.rnd.Intn(200), 8000+p.rnd.Intn(1000))
+	p.UrlCategoryList = ""
+	p.UserAgent = ""
+	p.HttpMethod = ""
+	p.FileType = ""
+	p.FileDigest = ""
+	p.Cloud = ""
+	p.ReportId = 0
+
+	host := hosts[p.rnd.Intn(len(hosts))]
+	file := files[p.rnd.Intn(len(files))]
+	switch p.Threat.Subtype {
+	case "url":
+		if !p.App.Web {
+			p.App = applications[0]
+			p.DstPort = p.App.Port
+		}
+		p.Category = urlCategories[p.rnd.Intn(len(urlCategories))]
+		p.Misc = host + "/"
+		p.UrlCategoryList = p.Category + ",low-risk"
+		p.UserAgent = random.UserAgent(p.rnd)
+		p.HttpMethod = strings.ToLower(random.HTTPMethod(p.rnd))
+	case "vulnerability":
+		p.Misc = ""
+		if p.App.Web {
+			p.Misc = host + "/"
+		}
+	case "spyware":
+		p.Misc = ""
+		p.Direction = "server-to-client"
+	default:
+		p.Misc = file
+		p.Direction = "server-to-client"
+		p.FileType = "pe"
+		if strings.HasSuffix(file, ".pdf") {
+			p.FileType = "pdf"
+		}
+		if p.Threat.Subtype == "wildfire" {
+			digest := make([]byte, 32)
+			p.rnd.Read(digest)
+			p.FileDigest = hex.EncodeToString(digest)
+			p.Cloud = "wildfire.paloaltonetworks.com"
+			p.ReportId = p.rnd.Int63n(1 << 40)
+		}
+	}
+}
+
+func (p *Panos) randomizeSystem() {
+	p.System = systemEvents[p.rnd.Intn(len(systemEvents))]
+	p.Subtype = p.System.Subtype
+	p.Vsys = ""
+}
+
+func (p *Panos) randomizeConfig() {
+	p.Config = configChanges[p.rnd.Intn(len(configChanges))]
+	p.Subtype = "0"
+	p.SrcUser = admins[p.rnd.Intn(len(admins))]
+	if p.Config.Command == "commit" {
+		p.Vsys = ""
+	}
+}
+
+func (p *Panos) randomizeGlobalProtect() {
+	p.GlobalProtect = globalProtectEvents[p.rnd.Intn(len(globalProtectEvents))]
+	p.Subtype = "0"
+	p.AuthMethod = authMethods[p.rnd.Intn(len(authMethods))]
+	p.TunnelType = tunnelTypes[p.rnd.Intn(len(tunnelTypes))]
+	p.MachineName = fmt.Sprintf("WKS-%04d", p.rnd.Intn(10000))
+	p.HostId = fmt.Sprintf("%08x-%04x-%04x-%04x-%012x", p.rnd.Uint32(), p.rnd.Intn(1<<16), p.rnd.Intn(1<<16), p.rnd.Intn(1<<16), p.rnd.Int63n(1<<48))
+	p.ClientSerial = fmt.Sprintf("C%09d", p.rnd.Intn(1e9))
+	p.ClientVersion = clientVersions[p.rnd.Intn(len(clientVersions))]
+	o := clientOses[p.rnd.Intn(len(clientOses))]
+	p.ClientOs, p.ClientOsVersion = o.os, o.version
+	p.ConnectMethod = connectMethods[p.rnd.Intn(len(connectMethods))]
+	p.Portal = "GP-Portal"
+	p.Gateway = ""
+	p.SelectionType = ""
+	p.ResponseTime = 0
+	p.Priority = ""
+	p.Elapsed = 0
+	switch p.GlobalProtect.Stage {
+	case "connected", "tunnel":
+		p.Gateway = "GP-Gateway"
+		p.SelectionType = selectionTypes[p.rnd.Intn(len(selectionTypes))]
+		p.ResponseTime = 10 + p.rnd.Intn(200)
+		p.Priority = priorities[p.rnd.Intn(len(priorities))]
+	case "logout":
+		p.Gateway = "GP-Gateway"
+		p.Elapsed = p.rnd.Intn(36000)
+	}
+}
@@ -0,0 +1,76 @@
+package panos
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNext(t *testing.T) {
+	// The number of fields of each log type in each version.
+	tests := map[string]struct {
+		logType string
+		fields  map[string]int
+	}{
+		"Traffic": {
+			logType: "TRAFFIC",
+			fields:  map[string]int{"8.1": 65, "9.0": 67, "9.1": 75, "10.0": 105, "10.1": 115, "10.2": 117},
+		},
+		"Threat": {
+			logType: "THREAT",
+			fields:  map[string]int{"8.1": 75, "9.0": 78, "9.1": 79, "10.0": 111, "10.1": 121, "10.2": 123},
+		},
+		"System": {
+			logType: "SYSTEM",
+			fields:  map[string]int{"8.1": 23, "9.0": 23, "9.1": 23, "10.0": 26, "10.1": 26, "10.2": 26},
+		},
+		"Config": {
+			logType: "CONFIG",
+			fields:  map[string]int{"8.1": 24, "9.0": 24, "9.1": 24, "10.0": 28, "10.1": 28, "10.2": 28},
+		},
+		"GlobalProtect": {
+			logType: "GLOBALPROTECT",
+			fields:  map[string]int{"9.1": 49, "10.0": 49, "10.1": 49, "10.2": 49},
+		},
+	}
+	testTime, err := time.Parse(time.RFC3339, "1970-01-02T03:04:05Z")
+	assert.Nil(t, err)
+	for name, tc := range tests {
+		for version, n := range tc.fields {
+			c, err := ucfg.NewFrom(map[string]interface{}{"type": Name, "event_type": strings.ToLower(tc.logType), "version": version})
+			assert.Nil(t, err, name)
+			g, err := New(c, rand.New(rand.NewSource(1)), clock.Fixed(testTime))
+			assert.Nil(t, err, name)
+			for i := 0; i < 100; i++ {
+				b, err := g.Next()
+				assert.Nil(t, err, name)
+				r := csv.NewReader(bytes.NewReader(b))
+				record, err := r.Read()
+				assert.Nil(t, err, "%s %s: %s", name, version, b)
+				assert.Equal(t, n, len(record), "%s %s: %s", name, version, b)
+				assert.Equal(t, tc.logType, record[3], name)
+				assert.Equal(t, "1970/01/02 03:04:05", record[1], name)
+			}
+		}
+	}
+}
+
+func TestSequence(t *testing.T) {
+	g, err := New(ucfg.MustNewFrom(map[string]interface{}{"type": Name}), rand.New(rand.NewSource(1)), clock.Real)
+	assert.Nil(t, err)
+	p := g.(*Panos)
+	for i := 0; i < 10; i++ {
+		before := p.Sequence
+		_, err := g.Next()
+		assert.Nil(t, err)
+		assert.Equal(t, before+1, p.Sequence)
+		assert.Equal(t, p.ruleUuids[p.Rule], p.RuleUuid)
+	}
+}
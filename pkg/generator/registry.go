@@ -2,15 +2,35 @@ package generator
 
 import (
 	"fmt"
+	"math/rand"
+	"sort"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 )
 
 // Factory is the function signature of each generators New function.
-// Given a config it returns a generator or an error.
-type Factory = func(*ucfg.Config) (Generator, error)
+// Given a config, the source of randomness the generator must draw
+// from and the clock it must read the time from it returns a generator
+// or an error.  Generators must not use the global math/rand functions
+// or time.Now, so that output is reproducible when the source is
+// seeded and records can be dated by a virtual clock.
+type Factory = func(*ucfg.Config, *rand.Rand, clock.Clock) (Generator, error)
 
-var registry = make(map[string]Factory)
+// Info describes a generator to users.  Config is the default config
+// struct of the generator, whose "config" tags and values are listed
+// as its options.  Example is a config to generate a sample record
+// with, for generators that cannot generate one with only "type" set.
+type Info struct {
+	Description string
+	Config      interface{}
+	Example     map[string]interface{}
+}
+
+var (
+	registry = make(map[string]Factory)
+	infos    = make(map[string]Info)
+)
 
 // Register associates a generator name with the generator factory.
 func Register(name string, factory Factory) error {
@@ -30,3 +50,31 @@ func GetFactory(name string) (Factory, error) {
 	}
 	return factory, nil
 }
+
+// RegisterInfo associates a generator name with its description.
+func RegisterInfo(name string, info Info) error {
+	if _, exists := infos[name]; exists {
+		return fmt.Errorf("Error registering info '%s': already registered", name)
+	}
+	infos[name] = info
+	return nil
+}
+
+// GetInfo retrieves the description of a generator, or returns an
+// error if there isn't a generator registered with that name.
+func GetInfo(name string) (Info, error) {
+	if _, exists := registry[name]; !exists {
+		return Info{}, fmt.Errorf("Input %s not registered", name)
+	}
+	return infos[name], nil
+}
+
+// Names returns the names of the registered generators in order.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,7 +1,6 @@
 package winlog
 
 import (
-	"math/rand"
 	"strconv"
 )
 
@@ -10,12 +9,12 @@ const event4634 = 4634
 // randomize4634 generates a random event with
 // ID 4634 (An account was logged off).
 func randomize4634(g *Generator) Event {
-	domain := RandomDomain()
-	computerName := RandomComputerName(domain)
+	domain := RandomDomain(g.rnd)
+	computerName := RandomComputerName(g.rnd, domain)
 
-	target := RandomUser()
+	target := RandomUser(g.rnd)
 
-	evt := RandomEvent(event4634, g.getTime())
+	evt := RandomEvent(g.rnd, event4634, g.getTime())
 	evt.Provider = Provider{
 		Name: "Microsoft-Windows-Security-Auditing",
 		GUID: "{54849625-5478-4994-A5BA-3E3B0328C30D}",
@@ -24,10 +23,10 @@ func randomize4634(g *Generator) Event {
 	evt.Computer = computerName
 	evt.EventData = EventData{
 		Data: []KeyValue{
-			{Key: "TargetUserSid", Value: RandomUserSID(target)},
+			{Key: "TargetUserSid", Value: g.userSID(target)},
 			{Key: "TargetUserName", Value: target},
 			{Key: "TargetDomainName", Value: domain},
-			{Key: "TargetLogonId", Value: "0x" + strconv.FormatInt(int64(rand.Intn(65536)), 16)},
+			{Key: "TargetLogonId", Value: "0x" + strconv.FormatInt(int64(g.rnd.Intn(65536)), 16)},
 			{Key: "LogonType", Value: "2"},
 		},
 	}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/leehinman/spigot/pkg/generator"
 	"github.com/leehinman/spigot/pkg/output/winlog"
 )
@@ -141,9 +142,14 @@ type Security struct {
 type Generator struct {
 	Event Event
 
-	eventID    *int
-	staticTime *time.Time
-	render     func(Event) ([]byte, error)
+	eventID *int
+	render  func(Event) ([]byte, error)
+	rnd     *rand.Rand
+	clock   clock.Clock
+
+	// SIDs handed out so far, so that a name always has the same SID.
+	serviceSIDs map[string]string
+	userSIDs    map[string]string
 }
 
 // Next produces the next Windows Event XML record.
@@ -153,7 +159,7 @@ func (g *Generator) Next() ([]byte, error) {
 	if g.eventID != nil {
 		eventID = *g.eventID
 	} else {
-		eventID = eventIDs[rand.Intn(len(eventIDs))]
+		eventID = eventIDs[g.rnd.Intn(len(eventIDs))]
 	}
 	fn, ok := eventRandomizers[eventID]
 	if !ok {
@@ -166,21 +172,22 @@ func (g *Generator) Next() ([]byte, error) {
 }
 
 func (g *Generator) getTime() time.Time {
-	if g.staticTime != nil {
-		return *g.staticTime
-	}
-
-	return time.Now()
+	return g.clock.Now()
 }
 
 // New is the factory for Windows Event XML objects.
-func New(cfg *ucfg.Config) (generator.Generator, error) {
+func New(cfg *ucfg.Config, r *rand.Rand, clk clock.Clock) (generator.Generator, error) {
 	c := defaultConfig()
 	if err := cfg.Unpack(&c); err != nil {
 		return nil, err
 	}
 
-	g := Generator{}
+	g := Generator{
+		rnd:         r,
+		clock:       clk,
+		serviceSIDs: map[string]string{},
+		userSIDs:    map[string]string{},
+	}
 	if c.EventID > 0 {
 		g.eventID = &c.EventID
 	}
@@ -205,4 +212,8 @@ func init() {
 	sort.Ints(eventIDs)
 
 	_ = generator.Register(Name, New)
+	generator.RegisterInfo(Name, generator.Info{
+		Description: "Windows Event Log XML records.",
+		Config:      defaultConfig(),
+	})
 }
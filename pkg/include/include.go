@@ -3,6 +3,8 @@
 package include
 
 import (
+	_ "github.com/leehinman/spigot/pkg/generator/aws/cloudtrail"
+	_ "github.com/leehinman/spigot/pkg/generator/aws/elb"
 	_ "github.com/leehinman/spigot/pkg/generator/aws/firewall"
 	_ "github.com/leehinman/spigot/pkg/generator/aws/vpcflow"
 	_ "github.com/leehinman/spigot/pkg/generator/cef"
@@ -10,6 +12,8 @@ import (
 	_ "github.com/leehinman/spigot/pkg/generator/citrix/cef"
 	_ "github.com/leehinman/spigot/pkg/generator/clf"
 	_ "github.com/leehinman/spigot/pkg/generator/fortinet/firewall"
+	_ "github.com/leehinman/spigot/pkg/generator/mix"
+	_ "github.com/leehinman/spigot/pkg/generator/paloalto/panos"
 	_ "github.com/leehinman/spigot/pkg/generator/winlog"
 	_ "github.com/leehinman/spigot/pkg/output/file"
 	_ "github.com/leehinman/spigot/pkg/output/rally"
@@ -0,0 +1,184 @@
+// Package metrics serves the counters of the runners over HTTP, in
+// the Prometheus text format on /metrics and as JSON on
+// /metrics/json.
+//
+//	Configuration.
+//
+//	"address" is required and is the host:port to listen on.
+//
+//	Example:
+//
+//	  metrics:
+//	    address: "localhost:9100"
+//	  runners:
+//	    - ...
+//
+//	Every metric is labeled with the runner's name, the generator
+//	type and the output types, comma separated.
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leehinman/spigot/pkg/runner"
+)
+
+// Config is the configuration of the metrics listener.
+type Config struct {
+	Address string `config:"address" validate:"required"`
+}
+
+// Source returns the runners to report on.
+type Source func() []*runner.Runner
+
+// Runner is the JSON view of the metrics of one runner.
+type Runner struct {
+	Runner       string    `json:"runner"`
+	Generator    string    `json:"generator"`
+	Output       string    `json:"output"`
+	Records      uint64    `json:"records"`
+	Bytes        uint64    `json:"bytes"`
+	WriteErrors  uint64    `json:"write_errors"`
+	Retries      uint64    `json:"retries"`
+	Reconnects   uint64    `json:"reconnects"`
+	Dropped      uint64    `json:"dropped"`
+	Lagged       uint64    `json:"lagged"`
+	Rate         float64   `json:"rate"`
+	WriteLatency Histogram `json:"write_latency"`
+}
+
+// Histogram is the JSON view of a latency histogram.  Bucket counts
+// are cumulative and durations are in seconds.
+type Histogram struct {
+	Buckets []Bucket `json:"buckets"`
+	Count   uint64   `json:"count"`
+	Sum     float64  `json:"sum"`
+}
+
+// Bucket is the number of durations less than or equal to LE.
+type Bucket struct {
+	LE    float64 `json:"le"`
+	Count uint64  `json:"count"`
+}
+
+// Snapshot returns the current metrics of the runners.
+func Snapshot(runners []*runner.Runner) []Runner {
+	metrics := make([]Runner, 0, len(runners))
+	for _, r := range runners {
+		s := r.Stats()
+		h := s.WriteLatency.Snapshot()
+		m := Runner{
+			Runner:      r.Name(),
+			Generator:   r.GeneratorType(),
+			Output:      strings.Join(r.OutputTypes(), ","),
+			Records:     s.Records.Load(),
+			Bytes:       s.Bytes.Load(),
+			WriteErrors: s.WriteErrors.Load(),
+			Retries:     s.Retries.Load(),
+			Reconnects:  s.Reconnects.Load(),
+			Dropped:     s.Dropped.Load(),
+			Lagged:      s.Lagged.Load(),
+			Rate:        s.Rate(),
+			WriteLatency: Histogram{
+				Count: h.Count,
+				Sum:   h.Sum.Seconds(),
+			},
+		}
+		for j, le := range runner.LatencyBuckets {
+			m.WriteLatency.Buckets = append(m.WriteLatency.Buckets, Bucket{LE: le, Count: h.Counts[j]})
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// Handler returns a handler serving the metrics of the runners
+// returned by source.
+func Handler(source Source) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		WritePrometheus(w, Snapshot(source()))
+	})
+	mux.HandleFunc("/metrics/json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(Snapshot(source()))
+	})
+	return mux
+}
+
+// counter describes a Prometheus counter or gauge and how to read it.
+type counter struct {
+	name  string
+	typ   string
+	help  string
+	value func(Runner) float64
+}
+
+var counters = []counter{
+	{"spigot_records_total", "counter", "Records written.", func(m Runner) float64 { return float64(m.Records) }},
+	{"spigot_bytes_total", "counter", "Bytes of generated records written.", func(m Runner) float64 { return float64(m.Bytes) }},
+	{"spigot_write_errors_total", "counter", "Failed writes to an output.", func(m Runner) float64 { return float64(m.WriteErrors) }},
+	{"spigot_retries_total", "counter", "Writes attempted again by the error policy.", func(m Runner) float64 { return float64(m.Retries) }},
+	{"spigot_reconnects_total", "counter", "Outputs created again by the error policy.", func(m Runner) float64 { return float64(m.Reconnects) }},
+	{"spigot_dropped_total", "counter", "Records dropped by the error policy.", func(m Runner) float64 { return float64(m.Dropped) }},
+	{"spigot_lagged_total", "counter", "Times the outputs fell behind the rate.", func(m Runner) float64 { return float64(m.Lagged) }},
+	{"spigot_rate_events_per_second", "gauge", "Configured events rate, 0 if not paced.", func(m Runner) float64 { return m.Rate }},
+}
+
+// WritePrometheus writes metrics in the Prometheus text format.
+func WritePrometheus(w io.Writer, metrics []Runner) {
+	for _, c := range counters {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", c.name, c.help, c.name, c.typ)
+		for _, m := range metrics {
+			fmt.Fprintf(w, "%s{%s} %s\n", c.name, labels(m), formatFloat(c.value(m)))
+		}
+	}
+
+	const name = "spigot_write_latency_seconds"
+	fmt.Fprintf(w, "# HELP %s Time taken to write a record to the outputs.\n# TYPE %s histogram\n", name, name)
+	for _, m := range metrics {
+		l := labels(m)
+		for _, b := range m.WriteLatency.Buckets {
+			fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, l, formatFloat(b.LE), b.Count)
+		}
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, l, m.WriteLatency.Count)
+		fmt.Fprintf(w, "%s_sum{%s} %s\n", name, l, formatFloat(m.WriteLatency.Sum))
+		fmt.Fprintf(w, "%s_count{%s} %d\n", name, l, m.WriteLatency.Count)
+	}
+}
+
+func labels(m Runner) string {
+	return fmt.Sprintf("runner=%s,generator=%s,output=%s", strconv.Quote(m.Runner), strconv.Quote(m.Generator), strconv.Quote(m.Output))
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// Serve listens on the configured address and serves the metrics of
+// the runners returned by source until ctx is done.
+func Serve(ctx context.Context, c Config, source Source) error {
+	l, err := net.Listen("tcp", c.Address)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{Handler: Handler(source), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	if err := srv.Serve(l); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
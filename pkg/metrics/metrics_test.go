@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/runner"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRunner(t *testing.T) *runner.Runner {
+	cfg := ucfg.MustNewFrom(map[string]interface{}{
+		"records": 10,
+		"rate":    map[string]interface{}{"events": 10000},
+		"generator": map[string]interface{}{
+			"type": "clf",
+		},
+		"output": map[string]interface{}{
+			"type":     "file",
+			"filename": filepath.Join(t.TempDir(), "clf.log"),
+		},
+	})
+	m := runner.NewManager(context.Background())
+	r, err := m.Start(cfg)
+	assert.NoError(t, err)
+	for _, res := range m.Wait() {
+		assert.NoError(t, res.Error)
+	}
+	return r
+}
+
+func TestHandler(t *testing.T) {
+	runners := []*runner.Runner{newRunner(t)}
+	srv := httptest.NewServer(Handler(func() []*runner.Runner { return runners }))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		path        string
+		contentType string
+		contains    []string
+	}{
+		"Prometheus": {
+			path:        "/metrics",
+			contentType: "text/plain; version=0.0.4",
+			contains: []string{
+				"# TYPE spigot_records_total counter\n",
+				`spigot_records_total{runner="0",generator="clf",output="file"} 10` + "\n",
+				`spigot_write_errors_total{runner="0",generator="clf",output="file"} 0` + "\n",
+				`spigot_rate_events_per_second{runner="0",generator="clf",output="file"} 10000` + "\n",
+				"# TYPE spigot_write_latency_seconds histogram\n",
+				`spigot_write_latency_seconds_bucket{runner="0",generator="clf",output="file",le="+Inf"} 10` + "\n",
+				`spigot_write_latency_seconds_count{runner="0",generator="clf",output="file"} 10` + "\n",
+			},
+		},
+		"JSON": {
+			path:        "/metrics/json",
+			contentType: "application/json",
+			contains:    []string{`"runner":"0"`, `"generator":"clf"`, `"records":10`},
+		},
+	}
+	for name, tc := range tests {
+		resp, err := http.Get(srv.URL + tc.path)
+		assert.NoError(t, err, name)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode, name)
+		assert.Equal(t, tc.contentType, resp.Header.Get("Content-Type"), name)
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err, name)
+		for _, s := range tc.contains {
+			assert.Contains(t, string(body), s, name)
+		}
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	metrics := Snapshot([]*runner.Runner{newRunner(t)})
+	assert.Len(t, metrics, 1)
+	m := metrics[0]
+	assert.Equal(t, uint64(10), m.Records)
+	assert.Equal(t, uint64(10), m.WriteLatency.Count)
+	assert.Len(t, m.WriteLatency.Buckets, len(runner.LatencyBuckets))
+	last := m.WriteLatency.Buckets[len(m.WriteLatency.Buckets)-1]
+	assert.LessOrEqual(t, last.Count, m.WriteLatency.Count)
+
+	b, err := json.Marshal(m)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"write_latency":{"buckets":[{"le":0.0001,`)
+}
@@ -5,6 +5,7 @@ import "fmt"
 type config struct {
 	Type      string `config:"type" validate:"required"`
 	Filename  string `config:"filename"`
+	Append    bool   `config:"append"`
 	Directory string `config:"directory"`
 	Pattern   string `config:"pattern"`
 	Delimiter string `config:"delimiter" validate:"required"`
@@ -18,6 +18,10 @@
 //	  delimiter: "\r\n"
 //
 // directory and pattern are used in os.CreateTemp call
+//
+// With "append: true" a filename is appended to instead of truncated.
+// A runner resuming from a checkpoint continues the file it was
+// writing, whether it is a filename or was created from the pattern.
 package file
 
 import (
@@ -39,10 +43,19 @@ type Output struct {
 	pWriteCloser io.WriteCloser
 	directory    string
 	pattern      string
+	// name is the name of the file being written and offset the
+	// number of bytes written to it.
+	name   string
+	offset int64
 }
 
 func init() {
 	output.Register(Name, New)
+	output.RegisterValidator(Name, Validate)
+	output.RegisterInfo(Name, output.Info{
+		Description: "Writes records to a file, or to a new file in a directory.",
+		Config:      defaultConfig(),
+	})
 }
 
 // New is the Factory for creating a new file output.  Calling this
@@ -61,29 +74,51 @@ func New(cfg *ucfg.Config) (output.Output, error) {
 			return nil, err
 		}
 	}
+	var offset int64
 	if c.Filename != "" {
-		pOsFile, err = os.Create(c.Filename)
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if c.Append {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		pOsFile, err = os.OpenFile(c.Filename, flags, 0666)
+		if err != nil {
+			return nil, err
+		}
+		info, err := pOsFile.Stat()
 		if err != nil {
+			pOsFile.Close()
 			return nil, err
 		}
+		offset = info.Size()
 	}
 	out := Output{
 		pWriteCloser: pOsFile,
 		delimiter:    c.Delimiter,
 		directory:    c.Directory,
 		pattern:      c.Pattern,
+		name:         pOsFile.Name(),
+		offset:       offset,
 	}
 	return &out, nil
 }
 
+// Validate is the Validator for the file output.  It checks cfg
+// without opening the file.
+func Validate(cfg *ucfg.Config) error {
+	c := defaultConfig()
+	return cfg.Unpack(&c)
+}
+
 // Write writes the log entry to the file handle that is opened with
 // new and appends the delimiter.
 func (o *Output) Write(b []byte) (n int, err error) {
 	j, err := o.pWriteCloser.Write(b)
+	o.offset += int64(j)
 	if err != nil {
 		return j, err
 	}
 	k, err := o.pWriteCloser.Write([]byte(o.delimiter))
+	o.offset += int64(k)
 	return j + k, err
 }
 
@@ -104,5 +139,33 @@ func (o *Output) NewInterval() error {
 		return err
 	}
 	o.pWriteCloser = pOsFile
+	o.name = pOsFile.Name()
+	o.offset = 0
+	return nil
+}
+
+// Position returns the file being written and how many bytes have
+// been written to it.
+func (o *Output) Position() output.Position {
+	return output.Position{File: o.name, Offset: o.offset}
+}
+
+// Resume continues writing p.File at p.Offset, dropping anything
+// after it.  A new file created from the pattern by New is removed.
+func (o *Output) Resume(p output.Position) error {
+	f, err := output.OpenPosition(p)
+	if err != nil {
+		return err
+	}
+	if err := o.pWriteCloser.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if o.directory != "" && o.name != p.File {
+		os.Remove(o.name)
+	}
+	o.pWriteCloser = f
+	o.name = p.File
+	o.offset = p.Offset
 	return nil
 }
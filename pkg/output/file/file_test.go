@@ -3,8 +3,12 @@ package file
 import (
 	"bytes"
 	"io"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -58,3 +62,49 @@ func TestWrite(t *testing.T) {
 		assert.Equal(t, []byte(tc.want), buf.Bytes(), name)
 	}
 }
+
+func TestResume(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "out.log")
+	cfg := ucfg.MustNewFrom(map[string]interface{}{"type": Name, "filename": name})
+
+	o, err := New(cfg)
+	assert.Nil(t, err)
+	_, err = o.Write([]byte("a"))
+	assert.Nil(t, err)
+	p := o.(output.Resumer).Position()
+	assert.Equal(t, output.Position{File: name, Offset: 2}, p)
+	// Written after the checkpoint and dropped when resuming.
+	_, err = o.Write([]byte("b"))
+	assert.Nil(t, err)
+	assert.Nil(t, o.Close())
+
+	tests := map[string]*ucfg.Config{
+		"Filename": ucfg.MustNewFrom(map[string]interface{}{"type": Name, "filename": name, "append": true}),
+		"Pattern":  ucfg.MustNewFrom(map[string]interface{}{"type": Name, "directory": dir, "pattern": "out_*"}),
+	}
+	for testName, cfg := range tests {
+		o, err := New(cfg)
+		assert.Nil(t, err, testName)
+		assert.Nil(t, o.(output.Resumer).Resume(p), testName)
+		_, err = o.Write([]byte("c"))
+		assert.Nil(t, err, testName)
+		assert.Equal(t, output.Position{File: name, Offset: 4}, o.(output.Resumer).Position(), testName)
+		assert.Nil(t, o.Close(), testName)
+
+		b, err := os.ReadFile(name)
+		assert.Nil(t, err, testName)
+		assert.Equal(t, "a\nc\n", string(b), testName)
+		// The new file from the pattern is removed.
+		files, err := os.ReadDir(dir)
+		assert.Nil(t, err, testName)
+		assert.Len(t, files, 1, testName)
+	}
+
+	o, err = New(tests["Filename"])
+	assert.Nil(t, err)
+	err = o.(output.Resumer).Resume(output.Position{File: name, Offset: 100})
+	assert.NotNil(t, err)
+	assert.Equal(t, name+" has 4 bytes, expected at least 100", err.Error())
+	assert.Nil(t, o.Close())
+}
@@ -33,3 +33,21 @@ func New(cfg *ucfg.Config) (Output, error) {
 	}
 	return factory(cfg)
 }
+
+// Validate checks cfg for the output that is specified by the "type"
+// without creating the output.  Outputs that have not registered a
+// Validator are only checked for a known type.
+func Validate(cfg *ucfg.Config) error {
+	c := config{}
+	err := cfg.Unpack(&c)
+	if err != nil {
+		return err
+	}
+	if _, err := GetFactory(c.Type); err != nil {
+		return err
+	}
+	if validator, ok := validators[c.Type]; ok {
+		return validator(cfg)
+	}
+	return nil
+}
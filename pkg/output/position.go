@@ -0,0 +1,48 @@
+package output
+
+import (
+	"fmt"
+	"os"
+)
+
+// Position is where an output that writes to a file is writing.
+type Position struct {
+	File   string `json:"file"`
+	Offset int64  `json:"offset"`
+}
+
+// Resumer is implemented by outputs that write to files, so that a
+// runner can save where they are writing and continue there after a
+// restart.
+//
+// Position returns the file being written and the number of bytes
+// written to it.
+//
+// Resume continues writing to p.File at p.Offset instead of the file
+// opened by New, dropping anything written to p.File after p.Offset.
+type Resumer interface {
+	Position() Position
+	Resume(p Position) error
+}
+
+// OpenPosition opens p.File for appending and truncates it to
+// p.Offset.  It fails if the file does not exist or is shorter than
+// p.Offset.
+func OpenPosition(p Position) (*os.File, error) {
+	f, err := os.OpenFile(p.File, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err == nil && info.Size() < p.Offset {
+		err = fmt.Errorf("%s has %d bytes, expected at least %d", p.File, info.Size(), p.Offset)
+	}
+	if err == nil {
+		err = f.Truncate(p.Offset)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
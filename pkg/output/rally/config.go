@@ -7,6 +7,7 @@ type config struct {
 	Directory string `config:"directory"`
 	Pattern   string `config:"pattern"`
 	Filename  string `config:"filename"`
+	Append    bool   `config:"append"`
 }
 
 func defaultConfig() config {
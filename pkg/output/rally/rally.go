@@ -14,6 +14,10 @@
 //	  pattern: "rally_*"
 //
 // directory and pattern are used in os.CreateTemp call
+//
+// With "append: true" a filename is appended to instead of truncated.
+// A runner resuming from a checkpoint continues the file it was
+// writing, whether it is a filename or was created from the pattern.
 package rally
 
 import (
@@ -34,6 +38,10 @@ type Output struct {
 	pWriteCloser io.WriteCloser
 	directory    string
 	pattern      string
+	// name is the name of the file being written and offset the
+	// number of bytes written to it.
+	name   string
+	offset int64
 }
 
 type entry struct {
@@ -42,6 +50,11 @@ type entry struct {
 
 func init() {
 	output.Register(Name, New)
+	output.RegisterValidator(Name, Validate)
+	output.RegisterInfo(Name, output.Info{
+		Description: "Writes records as ndjson for use as a Rally corpus.",
+		Config:      defaultConfig(),
+	})
 }
 
 // New is the Factory for creating a new rally output.  Calling this
@@ -60,20 +73,40 @@ func New(cfg *ucfg.Config) (output.Output, error) {
 			return nil, err
 		}
 	}
+	var offset int64
 	if c.Filename != "" {
-		pOsFile, err = os.Create(c.Filename)
+		flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		if c.Append {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		}
+		pOsFile, err = os.OpenFile(c.Filename, flags, 0666)
+		if err != nil {
+			return nil, err
+		}
+		info, err := pOsFile.Stat()
 		if err != nil {
+			pOsFile.Close()
 			return nil, err
 		}
+		offset = info.Size()
 	}
 	out := Output{
 		pWriteCloser: pOsFile,
 		directory:    c.Directory,
 		pattern:      c.Pattern,
+		name:         pOsFile.Name(),
+		offset:       offset,
 	}
 	return &out, nil
 }
 
+// Validate is the Validator for the rally output.  It checks cfg
+// without opening the file.
+func Validate(cfg *ucfg.Config) error {
+	c := defaultConfig()
+	return cfg.Unpack(&c)
+}
+
 // Write formats the log for rally and writes the data to the file
 // handle that was opened with New
 func (r *Output) Write(b []byte) (int, error) {
@@ -85,10 +118,12 @@ func (r *Output) Write(b []byte) (int, error) {
 		return 0, err
 	}
 	n, err := r.pWriteCloser.Write(jsonBytes)
+	r.offset += int64(n)
 	if err != nil {
 		return n, err
 	}
 	k, err := r.pWriteCloser.Write([]byte("\n"))
+	r.offset += int64(k)
 	return n + k, err
 }
 
@@ -109,5 +144,33 @@ func (o *Output) NewInterval() error {
 		return err
 	}
 	o.pWriteCloser = pOsFile
+	o.name = pOsFile.Name()
+	o.offset = 0
+	return nil
+}
+
+// Position returns the file being written and how many bytes have
+// been written to it.
+func (o *Output) Position() output.Position {
+	return output.Position{File: o.name, Offset: o.offset}
+}
+
+// Resume continues writing p.File at p.Offset, dropping anything
+// after it.  A new file created from the pattern by New is removed.
+func (o *Output) Resume(p output.Position) error {
+	f, err := output.OpenPosition(p)
+	if err != nil {
+		return err
+	}
+	if err := o.pWriteCloser.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if o.directory != "" && o.name != p.File {
+		os.Remove(o.name)
+	}
+	o.pWriteCloser = f
+	o.name = p.File
+	o.offset = p.Offset
 	return nil
 }
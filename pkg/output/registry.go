@@ -2,13 +2,29 @@ package output
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/elastic/go-ucfg"
 )
 
 type Factory = func(*ucfg.Config) (Output, error)
 
-var registry = make(map[string]Factory)
+// Validator checks the config of an output without creating it.
+type Validator = func(*ucfg.Config) error
+
+// Info describes an output to users.  Config is the default config
+// struct of the output, whose "config" tags and values are listed as
+// its options.
+type Info struct {
+	Description string
+	Config      interface{}
+}
+
+var (
+	registry   = make(map[string]Factory)
+	validators = make(map[string]Validator)
+	infos      = make(map[string]Info)
+)
 
 func Register(name string, factory Factory) error {
 	if _, exists := registry[name]; exists {
@@ -25,3 +41,42 @@ func GetFactory(name string) (Factory, error) {
 	}
 	return factory, nil
 }
+
+// RegisterValidator registers the Validator for the output name.
+// Outputs that connect or create files in their Factory should
+// register one, so their config can be checked without side effects.
+func RegisterValidator(name string, validator Validator) error {
+	if _, exists := validators[name]; exists {
+		return fmt.Errorf("Error registering validator '%s': already registered", name)
+	}
+	validators[name] = validator
+	return nil
+}
+
+// RegisterInfo associates an output name with its description.
+func RegisterInfo(name string, info Info) error {
+	if _, exists := infos[name]; exists {
+		return fmt.Errorf("Error registering info '%s': already registered", name)
+	}
+	infos[name] = info
+	return nil
+}
+
+// GetInfo retrieves the description of an output, or returns an error
+// if there isn't an output registered with that name.
+func GetInfo(name string) (Info, error) {
+	if _, exists := registry[name]; !exists {
+		return Info{}, fmt.Errorf("Output %s not registered", name)
+	}
+	return infos[name], nil
+}
+
+// Names returns the names of the registered outputs in order.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
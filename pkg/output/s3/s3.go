@@ -55,6 +55,11 @@ type S3Output struct {
 
 func init() {
 	output.Register(Name, New)
+	output.RegisterValidator(Name, Validate)
+	output.RegisterInfo(Name, output.Info{
+		Description: "Writes gzipped records to objects in an AWS S3 bucket.",
+		Config:      defaultConfig(),
+	})
 }
 
 // New is factory for creating a new S3Output
@@ -86,6 +91,13 @@ func New(cfg *ucfg.Config) (s output.Output, err error) {
 	return s, nil
 }
 
+// Validate is the Validator for the s3 output.  It checks cfg
+// without connecting to S3.
+func Validate(cfg *ucfg.Config) error {
+	c := defaultConfig()
+	return cfg.Unpack(&c)
+}
+
 // Write writes log entry to internal buffer
 func (s *S3Output) Write(b []byte) (n int, err error) {
 	j, err := s.gw.Write(b)
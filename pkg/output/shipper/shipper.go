@@ -26,6 +26,11 @@ type ShipperOutput struct {
 
 func init() {
 	output.Register(Name, New)
+	output.RegisterValidator(Name, Validate)
+	output.RegisterInfo(Name, output.Info{
+		Description: "Publishes records to the Elastic shipper over gRPC.",
+		Config:      defaultConfig(),
+	})
 }
 
 // New is factory for creating a new Shipper output
@@ -51,6 +56,13 @@ func New(cfg *ucfg.Config) (s output.Output, err error) {
 	return so, nil
 }
 
+// Validate is the Validator for the shipper output.  It checks cfg
+// without connecting to the shipper.
+func Validate(cfg *ucfg.Config) error {
+	c := defaultConfig()
+	return cfg.Unpack(&c)
+}
+
 func (s *ShipperOutput) Write(b []byte) (n int, err error) {
 	source := &messages.Source{
 		InputId:  s.config.InputId,
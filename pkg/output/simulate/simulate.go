@@ -50,6 +50,11 @@ type inputEvents struct {
 
 func init() {
 	output.Register(Name, New)
+	output.RegisterValidator(Name, Validate)
+	output.RegisterInfo(Name, output.Info{
+		Description: "Writes records as JSON for elastic-package pipeline tests.",
+		Config:      defaultConfig(),
+	})
 }
 
 // New is the Factory for creating a new simulate output.  Calling this
@@ -85,6 +90,13 @@ func New(cfg *ucfg.Config) (output.Output, error) {
 	return &out, nil
 }
 
+// Validate is the Validator for the simulate output.  It checks cfg
+// without opening the file.
+func Validate(cfg *ucfg.Config) error {
+	c := defaultConfig()
+	return cfg.Unpack(&c)
+}
+
 // Write formats the event and adds it to the internal slice of events.
 // actual writing will happen when Close is called.
 func (r *Output) Write(b []byte) (int, error) {
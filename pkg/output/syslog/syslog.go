@@ -35,6 +35,11 @@ type Output struct {
 
 func init() {
 	output.Register(Name, New)
+	output.RegisterValidator(Name, Validate)
+	output.RegisterInfo(Name, output.Info{
+		Description: "Sends records to a syslog server over TCP or UDP.",
+		Config:      defaultConfig(),
+	})
 }
 
 // New is the Factory for making a new syslog output
@@ -54,6 +59,13 @@ func New(cfg *ucfg.Config) (s output.Output, err error) {
 	return s, nil
 }
 
+// Validate is the Validator for the syslog output.  It checks cfg
+// without connecting to the syslog server.
+func Validate(cfg *ucfg.Config) error {
+	c := defaultConfig()
+	return cfg.Unpack(&c)
+}
+
 // Write sends the log message to the syslog server
 func (s *Output) Write(b []byte) (n int, err error) {
 	return s.pWC.Write(b)
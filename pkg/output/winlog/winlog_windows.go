@@ -47,6 +47,11 @@ type Output struct {
 
 func init() {
 	output.Register(Name, New)
+	output.RegisterValidator(Name, Validate)
+	output.RegisterInfo(Name, output.Info{
+		Description: "Writes records to the Windows event log.",
+		Config:      defaultConfig(),
+	})
 }
 
 // New is the Factory for creating a new winlog output.
@@ -76,6 +81,13 @@ func New(cfg *ucfg.Config) (output.Output, error) {
 	}, nil
 }
 
+// Validate is the Validator for the winlog output.  It checks cfg
+// without installing the event source.
+func Validate(cfg *ucfg.Config) error {
+	c := defaultConfig()
+	return cfg.Unpack(&c)
+}
+
 func (o *Output) Write(b []byte) (n int, err error) {
 	if o.log == nil {
 		return 0, errors.New("the output is closed and unusable")
@@ -1,6 +1,9 @@
 package random
 
-import "math/rand"
+import (
+	"fmt"
+	"math/rand"
+)
 
 var (
 	availabilityZones = [...]string{
@@ -165,23 +168,42 @@ var (
 )
 
 // AWSAvailabilityZone will return a random AWS Availability Zone.
-func AWSAvailabilityZone() string {
-	return availabilityZones[rand.Intn(len(availabilityZones))]
+func AWSAvailabilityZone(r *rand.Rand) string {
+	return availabilityZones[r.Intn(len(availabilityZones))]
 }
 
 // AWSAvailabilityZoneInRegion will return a random AWS Availability
 // Zone in the provided region. If the region cannot be found, an empty
 // string will be returned.
-func AWSAvailabilityZoneInRegion(region string) string {
+func AWSAvailabilityZoneInRegion(r *rand.Rand, region string) string {
 	regionAZs, ok := regionAZMap[region]
 	if !ok {
 		return ""
 	}
 
-	return regionAZs[rand.Intn(len(regionAZs))]
+	return regionAZs[r.Intn(len(regionAZs))]
 }
 
 // AWSRegion returns a random AWS region.
-func AWSRegion() string {
-	return regions[rand.Intn(len(regions))]
+func AWSRegion(r *rand.Rand) string {
+	return regions[r.Intn(len(regions))]
+}
+
+// AWSAccountID returns a random 12 digit AWS account ID.
+func AWSAccountID(r *rand.Rand) string {
+	return fmt.Sprintf("%012d", r.Int63n(1e12))
+}
+
+// ValidAWSAccountID returns whether id is an AWS account ID, which is
+// 12 digits.
+func ValidAWSAccountID(id string) bool {
+	if len(id) != 12 {
+		return false
+	}
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
 }
@@ -1,4 +1,6 @@
-// Package random provides functions for generating random objects using math/rand
+// Package random provides functions for generating random objects
+// using a caller provided *rand.Rand, so that each generator can draw
+// from its own, optionally seeded, source.
 package random
 
 import (
@@ -8,12 +10,12 @@ import (
 
 // IPv4 returns a random net.IP from the IPv4 address space.  No
 // effort is made to prevent non-routable addresses.
-func IPv4() net.IP {
-	u32 := rand.Uint32()
+func IPv4(r *rand.Rand) net.IP {
+	u32 := r.Uint32()
 	return net.IPv4(byte(u32&0xff), byte((u32>>8)&0xff), byte((u32>>16)&0xff), byte((u32>>24)&0xff))
 }
 
 // Port returns a random integer from 0 to 65535.
-func Port() int {
-	return rand.Intn(65536)
+func Port(r *rand.Rand) int {
+	return r.Intn(65536)
 }
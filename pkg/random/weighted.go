@@ -0,0 +1,39 @@
+package random
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+)
+
+// Weighted picks indexes with probability proportional to their
+// weights.
+type Weighted struct {
+	cumulative []float64
+}
+
+// NewWeighted returns a Weighted for the given weights.  Weights must
+// not be negative and at least one must be greater than zero.
+func NewWeighted(weights []float64) (*Weighted, error) {
+	w := &Weighted{cumulative: make([]float64, len(weights))}
+	var total float64
+	for i, v := range weights {
+		if v < 0 {
+			return nil, errors.New("weights must not be negative")
+		}
+		total += v
+		w.cumulative[i] = total
+	}
+	if total <= 0 {
+		return nil, errors.New("at least one weight must be greater than zero")
+	}
+	return w, nil
+}
+
+// Pick returns a random index into the weights given to NewWeighted.
+// An index with weight zero is never returned.
+func (w *Weighted) Pick(r *rand.Rand) int {
+	total := w.cumulative[len(w.cumulative)-1]
+	x := r.Float64() * total
+	return sort.Search(len(w.cumulative), func(i int) bool { return w.cumulative[i] > x })
+}
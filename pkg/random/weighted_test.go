@@ -0,0 +1,59 @@
+package random
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewWeighted(t *testing.T) {
+	tests := map[string]struct {
+		weights     []float64
+		hasError    bool
+		errorString string
+	}{
+		"Valid": {
+			weights: []float64{1, 0, 3},
+		},
+		"Negative": {
+			weights:     []float64{1, -1},
+			hasError:    true,
+			errorString: "weights must not be negative",
+		},
+		"All Zero": {
+			weights:     []float64{0, 0},
+			hasError:    true,
+			errorString: "at least one weight must be greater than zero",
+		},
+		"Empty": {
+			weights:     nil,
+			hasError:    true,
+			errorString: "at least one weight must be greater than zero",
+		},
+	}
+	for name, tc := range tests {
+		_, err := NewWeighted(tc.weights)
+		if tc.hasError {
+			assert.Error(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.NoError(t, err, name)
+		}
+	}
+}
+
+func TestWeightedPick(t *testing.T) {
+	w, err := NewWeighted([]float64{1, 0, 3})
+	assert.NoError(t, err)
+
+	r := rand.New(rand.NewSource(1))
+	counts := make([]int, 3)
+	for i := 0; i < 10000; i++ {
+		counts[w.Pick(r)]++
+	}
+	assert.Zero(t, counts[1])
+	assert.InDelta(t, 2500, counts[0], 200)
+	assert.InDelta(t, 7500, counts[2], 200)
+}
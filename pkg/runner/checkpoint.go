@@ -0,0 +1,210 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+// Checkpoint is the progress of a runner.  It is saved at every
+// interval and when the runner stops, so that a long job can be
+// resumed after a restart instead of starting again from zero.
+type Checkpoint struct {
+	// Hash is the hash of the runner's config.  A runner only
+	// resumes from a checkpoint of the same config.
+	Hash    string        `json:"hash"`
+	Records uint64        `json:"records"`
+	Bytes   uint64        `json:"bytes"`
+	Elapsed time.Duration `json:"elapsed"`
+	// Draws is the number of values drawn from the source of each
+	// generator, if the runner has a "seed".
+	Draws []uint64 `json:"draws,omitempty"`
+	// Time is the time of the clock, if the runner has a "time"
+	// block.
+	Time *time.Time `json:"time,omitempty"`
+	// Outputs holds the position of each output that writes to a
+	// file, and nil for the others.
+	Outputs []*output.Position `json:"outputs,omitempty"`
+	// Done is set once the runner has finished, rather than being
+	// stopped or failing.
+	Done bool `json:"done,omitempty"`
+}
+
+// Checkpoints holds the checkpoints of runners by name and saves them
+// to a state file.
+type Checkpoints struct {
+	path string
+
+	mu      sync.Mutex
+	runners map[string]Checkpoint
+}
+
+// NewCheckpoints returns Checkpoints saved to path.  If resume is true
+// the checkpoints already in path are loaded, and runners started with
+// the same name and config continue from them.  Otherwise path is
+// overwritten when the first checkpoint is saved.
+func NewCheckpoints(path string, resume bool) (*Checkpoints, error) {
+	c := &Checkpoints{path: path, runners: make(map[string]Checkpoint)}
+	if !resume {
+		return c, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &c.runners); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// get returns the checkpoint of the runner name with the config hash,
+// if there is one.
+func (c *Checkpoints) get(name, hash string) (Checkpoint, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cp, ok := c.runners[name]
+	return cp, ok && cp.Hash == hash
+}
+
+// save stores the checkpoint of the runner name and writes all
+// checkpoints to the state file.  The file is replaced atomically, so
+// it holds either the old or the new checkpoints after a crash.
+func (c *Checkpoints) save(name string, cp Checkpoint) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.runners[name] = cp
+	b, err := json.MarshalIndent(c.runners, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+	if err = errors.Join(err, f.Close()); err == nil {
+		err = os.Rename(f.Name(), c.path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// countingSource is a rand.Source64 that counts the values drawn from
+// it, so that its state can be saved as the seed and the count.  The
+// count may be read while values are drawn.
+type countingSource struct {
+	src   rand.Source64
+	draws atomic.Uint64
+}
+
+// newCountingSource returns a source seeded with seed that has
+// already had draws values drawn.
+func newCountingSource(seed int64, draws uint64) *countingSource {
+	s := &countingSource{src: rand.NewSource(seed).(rand.Source64)}
+	for i := uint64(0); i < draws; i++ {
+		s.src.Uint64()
+	}
+	s.draws.Store(draws)
+	return s
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws.Add(1)
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws.Add(1)
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.draws.Store(0)
+}
+
+// output returns the position of output i, or nil if there is none.
+// It may be called on a nil checkpoint.
+func (cp *Checkpoint) output(i int) *output.Position {
+	if cp == nil || i >= len(cp.Outputs) {
+		return nil
+	}
+	return cp.Outputs[i]
+}
+
+// newResumedSink creates the sink of an output that continues writing
+// at p.  A filename in the config is opened for appending, so that it
+// is not truncated before the output resumes.
+func newResumedSink(cfg *ucfg.Config, c sinkConfig, stats *Stats, p output.Position) (*sink, error) {
+	rc, err := ucfg.NewFrom(cfg, ucfg.PathSep("."))
+	if err != nil {
+		return nil, err
+	}
+	if err := rc.SetBool("append", -1, true); err != nil {
+		return nil, err
+	}
+	s, err := newSink(rc, c, stats)
+	if err != nil {
+		return nil, err
+	}
+	o, ok := s.output.(output.Resumer)
+	if !ok {
+		s.output.Close()
+		return nil, fmt.Errorf("output %s cannot resume from a position", s.typ)
+	}
+	if err := o.Resume(p); err != nil {
+		s.output.Close()
+		return nil, fmt.Errorf("output %s: resuming: %w", s.typ, err)
+	}
+	return s, nil
+}
+
+// checkpoint saves the progress of the runner, if it has a state
+// file.  It is called between records, at every interval and when
+// the runner stops.
+func (r *Runner) checkpoint(done bool) {
+	if r.checkpoints == nil {
+		return
+	}
+	cp := Checkpoint{
+		Hash:    r.hash,
+		Records: r.stats.Records.Load(),
+		Bytes:   r.stats.Bytes.Load(),
+		Elapsed: r.resumed + r.Elapsed(),
+		Done:    done,
+	}
+	for _, s := range r.sources {
+		cp.Draws = append(cp.Draws, s.draws.Load())
+	}
+	if r.clock != nil {
+		t := r.clock.Now()
+		cp.Time = &t
+	}
+	for _, s := range r.sinks {
+		var p *output.Position
+		if o, ok := s.output.(output.Resumer); ok {
+			pos := o.Position()
+			p = &pos
+		}
+		cp.Outputs = append(cp.Outputs, p)
+	}
+	if err := r.checkpoints.save(r.name, cp); err != nil {
+		log.Printf("runner %s: saving checkpoint: %v", r.name, err)
+	}
+}
@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	state := filepath.Join(dir, "state.json")
+	out := filepath.Join(dir, "out.log")
+	config := func(maxRecords int) *ucfg.Config {
+		return ucfg.MustNewFrom(map[string]interface{}{
+			"generator":   map[string]interface{}{"type": "clf"},
+			"output":      map[string]interface{}{"type": "file", "filename": out},
+			"interval":    "1ms",
+			"records":     10,
+			"max_records": maxRecords,
+			"seed":        42,
+		}, ucfg.PathSep("."))
+	}
+	lines := func() []string {
+		b, err := os.ReadFile(out)
+		assert.Nil(t, err)
+		return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	}
+	hash, err := hashConfig(config(50))
+	assert.Nil(t, err)
+
+	// Stop after 20 records, as if the runner with 50 had crashed
+	// after its checkpoint and a partial record.
+	checkpoints, err := NewCheckpoints(state, false)
+	assert.Nil(t, err)
+	r, err := newRunner(config(20), checkpoints, "backfill", hash)
+	assert.Nil(t, err)
+	assert.Nil(t, r.Execute(context.Background()))
+	cp, ok := checkpoints.get("backfill", hash)
+	assert.True(t, ok)
+	assert.True(t, cp.Done)
+	cp.Done = false
+	assert.Nil(t, checkpoints.save("backfill", cp))
+	first := lines()
+	assert.Len(t, first, 20)
+	f, err := os.OpenFile(out, os.O_WRONLY|os.O_APPEND, 0)
+	assert.Nil(t, err)
+	_, err = f.WriteString("partial")
+	assert.Nil(t, err)
+	assert.Nil(t, f.Close())
+
+	checkpoints, err = NewCheckpoints(state, true)
+	assert.Nil(t, err)
+	_, ok = checkpoints.get("backfill", "other")
+	assert.False(t, ok)
+	r, err = newRunner(config(50), checkpoints, "backfill", hash)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(20), r.Stats().Records.Load())
+	assert.Nil(t, r.Execute(context.Background()))
+	assert.Equal(t, uint64(50), r.Stats().Records.Load())
+
+	all := lines()
+	assert.Len(t, all, 50)
+	assert.Equal(t, first, all[:20])
+	// The resumed runner does not repeat the records it wrote.
+	assert.NotEqual(t, first, all[20:40])
+	cp, ok = checkpoints.get("backfill", hash)
+	assert.True(t, ok)
+	assert.True(t, cp.Done)
+	assert.Equal(t, uint64(50), cp.Records)
+}
+
+func TestCountingSource(t *testing.T) {
+	s := newCountingSource(1, 0)
+	for i := 0; i < 5; i++ {
+		s.Int63()
+	}
+	assert.Equal(t, uint64(5), s.draws.Load())
+	assert.Equal(t, s.Uint64(), newCountingSource(1, 5).Uint64())
+}
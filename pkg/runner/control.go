@@ -0,0 +1,235 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+)
+
+const (
+	StateCreated = "created"
+	StateRunning = "running"
+	StatePaused  = "paused"
+	StateStopped = "stopped"
+)
+
+// control holds the state of a runner that is changed from other
+// goroutines while it executes.  The runner applies pending changes
+// before writing each record.
+type control struct {
+	mu      sync.Mutex
+	state   string
+	resumed chan struct{}
+	pending *tuneConfig
+	start   time.Time
+	stop    time.Time
+}
+
+// tuneConfig holds the settings of a runner that may be changed while
+// it executes.  Unset fields are left as they are.
+type tuneConfig struct {
+	Records  *int           `config:"records"`
+	Interval *time.Duration `config:"interval"`
+	Rate     *rateConfig    `config:"rate"`
+}
+
+func (c *tuneConfig) Validate() error {
+	if c.Records != nil && *c.Records < 1 {
+		return fmt.Errorf("'%d' is not a valid value for 'records' expected >= 1", *c.Records)
+	}
+	if c.Interval != nil && *c.Interval <= 0 {
+		return fmt.Errorf("'%v' is not a valid value for 'interval' expected > 0", *c.Interval)
+	}
+	return nil
+}
+
+// Info describes a runner and its current settings.  Events is the
+// current events rate, which follows the profile if there is one.
+type Info struct {
+	Name      string
+	Generator string
+	Outputs   []string
+	State     string
+	Records   int
+	Interval  time.Duration
+	Events    float64
+	Bytes     float64
+	Elapsed   time.Duration
+	Written   uint64
+	Error     string
+}
+
+// Name returns the name of the runner.
+func (r *Runner) Name() string {
+	return r.name
+}
+
+// State returns whether the runner is created, running, paused or
+// stopped.
+func (r *Runner) State() string {
+	r.ctl.mu.Lock()
+	defer r.ctl.mu.Unlock()
+	return r.ctl.state
+}
+
+// Info returns a description of the runner and its current settings.
+func (r *Runner) Info() Info {
+	r.ctl.mu.Lock()
+	defer r.ctl.mu.Unlock()
+	info := Info{
+		Name:      r.name,
+		Generator: r.generatorType,
+		Outputs:   r.OutputTypes(),
+		State:     r.ctl.state,
+		Records:   r.config.Records,
+		Interval:  r.config.Interval,
+		Elapsed:   r.elapsed(),
+		Written:   r.stats.Records.Load(),
+		Events:    r.stats.Rate(),
+	}
+	if r.pacer != nil {
+		info.Bytes = r.pacer.bytes
+	}
+	return info
+}
+
+// Pause stops the runner from writing until Resume is called.  The
+// outputs are kept open.
+func (r *Runner) Pause() error {
+	r.ctl.mu.Lock()
+	defer r.ctl.mu.Unlock()
+	switch r.ctl.state {
+	case StatePaused:
+		return nil
+	case StateStopped:
+		return fmt.Errorf("runner %s has stopped", r.name)
+	}
+	r.ctl.state = StatePaused
+	r.ctl.resumed = make(chan struct{})
+	return nil
+}
+
+// Resume lets a paused runner continue writing.
+func (r *Runner) Resume() error {
+	r.ctl.mu.Lock()
+	defer r.ctl.mu.Unlock()
+	switch r.ctl.state {
+	case StatePaused:
+	case StateStopped:
+		return fmt.Errorf("runner %s has stopped", r.name)
+	default:
+		return nil
+	}
+	r.ctl.state = StateRunning
+	if r.ctl.start.IsZero() {
+		r.ctl.state = StateCreated
+	}
+	close(r.ctl.resumed)
+	r.ctl.resumed = nil
+	return nil
+}
+
+// Tune changes "records", "interval" and "rate" of the runner while
+// it executes.  cfg uses the same keys as the runner config, and
+// settings not in cfg are left as they are.  "interval" can only be
+// changed on a runner that has one, and "rate" replaces both "events"
+// and "bytes", adding pacing to a runner without a rate.  A runner
+// with a "profile" can not be tuned, its profile sets the rate.
+func (r *Runner) Tune(cfg *ucfg.Config) error {
+	var t tuneConfig
+	if err := cfg.Unpack(&t); err != nil {
+		return err
+	}
+	r.ctl.mu.Lock()
+	defer r.ctl.mu.Unlock()
+	if r.ctl.state == StateStopped {
+		return fmt.Errorf("runner %s has stopped", r.name)
+	}
+	if r.config.Profile != nil && (t.Records != nil || t.Interval != nil || t.Rate != nil) {
+		return fmt.Errorf("runner %s has a 'profile', 'records', 'interval' and 'rate' can not be changed", r.name)
+	}
+	if t.Interval != nil && r.config.Interval == 0 {
+		return fmt.Errorf("runner %s has no 'interval' to change", r.name)
+	}
+	if r.ctl.pending == nil {
+		r.ctl.pending = &tuneConfig{}
+	}
+	if t.Records != nil {
+		r.ctl.pending.Records = t.Records
+	}
+	if t.Interval != nil {
+		r.ctl.pending.Interval = t.Interval
+	}
+	if t.Rate != nil {
+		r.ctl.pending.Rate = t.Rate
+	}
+	return nil
+}
+
+// checkControl is called by the runner before writing each record.
+// It applies pending changes and blocks while the runner is paused.
+func (r *Runner) checkControl(ctx context.Context) error {
+	r.ctl.mu.Lock()
+	if r.ctl.pending != nil {
+		r.applyTuning(r.ctl.pending)
+		r.ctl.pending = nil
+	}
+	resumed := r.ctl.resumed
+	r.ctl.mu.Unlock()
+
+	if resumed == nil {
+		return nil
+	}
+	select {
+	case <-resumed:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	// Do not try to catch up on the time spent paused.
+	if r.pacer != nil {
+		r.pacer.reset()
+	}
+	return nil
+}
+
+// applyTuning changes the settings of the runner.  It is called with
+// r.ctl.mu held from the runner's goroutine.
+func (r *Runner) applyTuning(t *tuneConfig) {
+	if t.Records != nil {
+		r.config.Records = *t.Records
+	}
+	if t.Interval != nil {
+		r.config.Interval = *t.Interval
+	}
+	if t.Rate != nil {
+		if r.pacer == nil {
+			r.pacer = newPacer(*t.Rate)
+			r.pacer.reset()
+		} else {
+			r.pacer.events, r.pacer.bytes = t.Rate.Events, t.Rate.Bytes
+		}
+		r.stats.setRate(t.Rate.Events)
+	}
+}
+
+func (r *Runner) setState(state string) {
+	r.ctl.mu.Lock()
+	defer r.ctl.mu.Unlock()
+	switch state {
+	case StateRunning:
+		r.ctl.start = time.Now()
+		if r.ctl.state == StatePaused {
+			return
+		}
+	case StateStopped:
+		r.ctl.stop = time.Now()
+		if r.ctl.resumed != nil {
+			close(r.ctl.resumed)
+			r.ctl.resumed = nil
+		}
+	}
+	r.ctl.state = state
+}
@@ -0,0 +1,170 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
+	"github.com/leehinman/spigot/pkg/generator"
+)
+
+const (
+	DelayUniform     = "uniform"
+	DelayExponential = "exponential"
+)
+
+var delayDistributions = []string{DelayUniform, DelayExponential}
+
+// disorderConfig is the "disorder" block of a runner.  Each part is
+// optional.
+type disorderConfig struct {
+	Late      *lateConfig      `config:"late"`
+	Reorder   *reorderConfig   `config:"reorder"`
+	Duplicate *duplicateConfig `config:"duplicate"`
+}
+
+// lateConfig dates "fraction" of the records back by a random delay.
+// With the uniform distribution the delay is between 0 and "delay",
+// with the exponential distribution "delay" is the mean.
+type lateConfig struct {
+	Fraction     float64       `config:"fraction" validate:"required"`
+	Delay        time.Duration `config:"delay" validate:"required"`
+	Distribution string        `config:"distribution"`
+}
+
+func (c *lateConfig) Validate() error {
+	if c.Fraction < 0 || c.Fraction > 1 {
+		return fmt.Errorf("'%v' is not a valid value for 'fraction' expected 0 <= fraction <= 1", c.Fraction)
+	}
+	if c.Delay <= 0 {
+		return fmt.Errorf("'%v' is not a valid value for 'delay' expected > 0", c.Delay)
+	}
+	switch c.Distribution {
+	case "", DelayUniform, DelayExponential:
+	default:
+		return fmt.Errorf("'%s' is not a valid value for 'distribution' expected one of %s", c.Distribution, strings.Join(delayDistributions, ", "))
+	}
+	return nil
+}
+
+// delay returns a random delay, or 0 for records that are not late.
+func (c *lateConfig) delay(r *rand.Rand) time.Duration {
+	if r.Float64() >= c.Fraction {
+		return 0
+	}
+	if c.Distribution == DelayExponential {
+		return time.Duration(r.ExpFloat64() * float64(c.Delay))
+	}
+	return time.Duration(r.Int63n(int64(c.Delay)))
+}
+
+// reorderConfig holds back "window" records and writes them in random
+// order.
+type reorderConfig struct {
+	Window int `config:"window" validate:"required"`
+}
+
+func (c *reorderConfig) Validate() error {
+	if c.Window < 2 {
+		return fmt.Errorf("'%d' is not a valid value for 'window' expected >= 2", c.Window)
+	}
+	return nil
+}
+
+// duplicateConfig writes "fraction" of the records twice.
+type duplicateConfig struct {
+	Fraction float64 `config:"fraction" validate:"required"`
+}
+
+func (c *duplicateConfig) Validate() error {
+	if c.Fraction < 0 || c.Fraction > 1 {
+		return fmt.Errorf("'%v' is not a valid value for 'fraction' expected 0 <= fraction <= 1", c.Fraction)
+	}
+	return nil
+}
+
+// lateClock is the clock of a generator when "late" is set.  It is
+// offset by the delay of the record being generated.
+type lateClock struct {
+	clock.Clock
+	offset time.Duration
+}
+
+func (c *lateClock) Now() time.Time {
+	return c.Clock.Now().Add(-c.offset)
+}
+
+// lateGenerator picks the delay of each record before asking the
+// generator for it.  Generators that take the time for the next
+// record at the end of Next date that record instead, which makes no
+// difference to how many records are late.
+type lateGenerator struct {
+	generator.Generator
+	late  *lateConfig
+	clock *lateClock
+	rnd   *rand.Rand
+}
+
+// newLateGenerator returns the generator configured by cfg with a
+// clock that dates records late as set by late.
+func newLateGenerator(cfg *ucfg.Config, gr *rand.Rand, clk clock.Clock, late *lateConfig, r *rand.Rand) (generator.Generator, error) {
+	lc := &lateClock{Clock: clk}
+	g, err := generator.New(cfg, gr, lc)
+	if err != nil {
+		return nil, err
+	}
+	return &lateGenerator{Generator: g, late: late, clock: lc, rnd: r}, nil
+}
+
+func (g *lateGenerator) Next() ([]byte, error) {
+	g.clock.offset = g.late.delay(g.rnd)
+	return g.Generator.Next()
+}
+
+// disorder sits between the generators and the outputs.  It writes
+// some records twice and, with "reorder", holds back a window of
+// records and releases a random one each time a record is needed.
+type disorder struct {
+	duplicate *duplicateConfig
+	size      int
+	reorder   bool
+	window    [][]byte
+	rnd       *rand.Rand
+}
+
+func newDisorder(c disorderConfig, r *rand.Rand) *disorder {
+	d := &disorder{duplicate: c.Duplicate, size: 1, rnd: r}
+	if c.Reorder != nil {
+		d.size = c.Reorder.Window
+		d.reorder = true
+	}
+	return d
+}
+
+// next fills the window from next and returns one record from it.
+func (d *disorder) next(ctx context.Context, next func(context.Context) ([]byte, error)) ([]byte, error) {
+	for len(d.window) < d.size {
+		b, err := next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// Generators may reuse their buffer on the next call.
+		b = append([]byte(nil), b...)
+		d.window = append(d.window, b)
+		if d.duplicate != nil && d.rnd.Float64() < d.duplicate.Fraction {
+			d.window = append(d.window, b)
+		}
+	}
+	i := 0
+	if d.reorder {
+		i = d.rnd.Intn(len(d.window))
+	}
+	b := d.window[i]
+	copy(d.window[i:], d.window[i+1:])
+	d.window = d.window[:len(d.window)-1]
+	return b, nil
+}
@@ -0,0 +1,51 @@
+package runner
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds, in seconds, of the buckets of
+// a Histogram.
+var LatencyBuckets = [...]float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// Histogram counts durations in LatencyBuckets.  It is updated
+// atomically and may be read while it is being updated.
+type Histogram struct {
+	// counts has one more entry than LatencyBuckets for durations
+	// above the last bound.
+	counts [len(LatencyBuckets) + 1]atomic.Uint64
+	sum    atomic.Int64
+}
+
+// HistogramSnapshot is a copy of a Histogram.  Counts are cumulative,
+// Counts[i] is the number of durations <= LatencyBuckets[i].
+type HistogramSnapshot struct {
+	Counts []uint64
+	Count  uint64
+	Sum    time.Duration
+}
+
+// Observe adds d to the histogram.
+func (h *Histogram) Observe(d time.Duration) {
+	s := d.Seconds()
+	i := 0
+	for i < len(LatencyBuckets) && s > LatencyBuckets[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.sum.Add(int64(d))
+}
+
+// Snapshot returns a copy of the histogram.
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	snap := HistogramSnapshot{Counts: make([]uint64, len(LatencyBuckets))}
+	for i := range h.counts {
+		snap.Count += h.counts[i].Load()
+		if i < len(LatencyBuckets) {
+			snap.Counts[i] = snap.Count
+		}
+	}
+	snap.Sum = time.Duration(h.sum.Load())
+	return snap
+}
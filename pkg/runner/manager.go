@@ -0,0 +1,359 @@
+package runner
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+
+	"github.com/elastic/go-ucfg"
+)
+
+// Result is the outcome of a runner started by a Manager.
+type Result struct {
+	Runner *Runner
+	Error  error
+}
+
+// Manager starts runners and keeps track of them, so that they can be
+// listed and controlled while they execute, and replaced when the
+// config is reloaded.  Runner names are unique among the runners that
+// are executing.
+type Manager struct {
+	ctx context.Context
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	running int
+	entries []*entry
+	byName  map[string]*entry
+	// checkpoints is where runners save their progress, if there is a
+	// state file.
+	checkpoints *Checkpoints
+}
+
+type entry struct {
+	runner *Runner
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+	// hash is the hash of the config of a runner started by Reload,
+	// and empty for one started by Start.
+	hash string
+}
+
+type nameConfig struct {
+	Name string `config:"name"`
+}
+
+// NewManager returns a Manager whose runners are cancelled when ctx
+// is done.
+func NewManager(ctx context.Context) *Manager {
+	m := &Manager{
+		ctx:    ctx,
+		byName: make(map[string]*entry),
+	}
+	m.cond = sync.NewCond(&m.mu)
+	return m
+}
+
+// UseCheckpoints makes runners started afterwards save their progress
+// to c, and continue from the checkpoint in c of the same name and
+// config if there is one.
+func (m *Manager) UseCheckpoints(c *Checkpoints) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checkpoints = c
+}
+
+// Start creates a runner from cfg and executes it in the background.
+// If cfg has no "name" the runner is named by the lowest number not
+// used by another runner.
+func (m *Manager) Start(cfg *ucfg.Config) (*Runner, error) {
+	var n nameConfig
+	if err := cfg.Unpack(&n); err != nil {
+		return nil, err
+	}
+	return m.start(cfg, n.Name, "")
+}
+
+// Reload makes the runners started by Reload match cfgs.  Runners are
+// matched by "name", which defaults to their index in cfgs.  Runners
+// whose name is no longer in cfgs are stopped, runners whose config
+// changed are stopped and started again with the new config, new
+// runners are started and unchanged runners are left alone, even if
+// they have finished.  Stopped
+// runners close their outputs before their replacements start.
+// Runners started by Start are not affected.  Errors of individual
+// runners do not prevent the others from being reloaded.
+func (m *Manager) Reload(cfgs []*ucfg.Config) error {
+	type wanted struct {
+		cfg  *ucfg.Config
+		name string
+		hash string
+	}
+	var errs []error
+	var want []wanted
+	names := make(map[string]bool)
+	for i, cfg := range cfgs {
+		var n nameConfig
+		if err := cfg.Unpack(&n); err != nil {
+			errs = append(errs, fmt.Errorf("runner %d: %w", i, err))
+			continue
+		}
+		if n.Name == "" {
+			n.Name = strconv.Itoa(i)
+		}
+		if names[n.Name] {
+			errs = append(errs, fmt.Errorf("runner %d: name %s is used by another runner", i, n.Name))
+			continue
+		}
+		names[n.Name] = true
+		h, err := hashConfig(cfg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("runner %s: %w", n.Name, err))
+			continue
+		}
+		want = append(want, wanted{cfg: cfg, name: n.Name, hash: h})
+	}
+
+	m.mu.Lock()
+	// Hold a place so Wait does not return while runners are replaced.
+	m.running++
+	var stop []*entry
+	keep := make(map[string]bool)
+	for name, e := range m.byName {
+		if e.hash == "" {
+			continue
+		}
+		changed := true
+		for _, w := range want {
+			if w.name == name {
+				changed = w.hash != e.hash
+			}
+		}
+		switch {
+		case !changed:
+			keep[name] = true
+		case e.executing():
+			stop = append(stop, e)
+		}
+	}
+	m.mu.Unlock()
+	defer m.release()
+
+	for _, e := range stop {
+		e.cancel()
+	}
+	for _, e := range stop {
+		<-e.done
+	}
+
+	for _, w := range want {
+		if keep[w.name] {
+			continue
+		}
+		if m.finished(w.name, w.hash) {
+			log.Printf("runner %s: finished before the checkpoint, not started", w.name)
+			continue
+		}
+		if _, err := m.start(w.cfg, w.name, w.hash); err != nil {
+			errs = append(errs, fmt.Errorf("runner %s: %w", w.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Manager) start(cfg *ucfg.Config, name, hash string) (*Runner, error) {
+	m.mu.Lock()
+	if name == "" {
+		for i := 0; name == ""; i++ {
+			if _, ok := m.byName[strconv.Itoa(i)]; !ok {
+				name = strconv.Itoa(i)
+			}
+		}
+	}
+	if e, ok := m.byName[name]; ok && e.executing() {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("runner %s is already running", name)
+	}
+	// Reserve the name while the runner is created.
+	reserved := &entry{done: make(chan struct{})}
+	m.byName[name] = reserved
+	checkpoints := m.checkpoints
+	m.mu.Unlock()
+
+	// Runners started by Start are checkpointed by their config too.
+	cpHash := hash
+	var err error
+	if checkpoints != nil && cpHash == "" {
+		cpHash, err = hashConfig(cfg)
+	}
+	var r Runner
+	if err == nil {
+		r, err = newRunner(cfg, checkpoints, name, cpHash)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		delete(m.byName, name)
+		return nil, err
+	}
+	r.name = name
+
+	ctx, cancel := context.WithCancel(m.ctx)
+	e := &entry{runner: &r, cancel: cancel, done: make(chan struct{}), hash: hash}
+	m.entries = append(m.entries, e)
+	m.byName[name] = e
+	m.running++
+
+	go func() {
+		defer m.release()
+		defer close(e.done)
+		defer cancel()
+		err := e.runner.Execute(ctx)
+		m.mu.Lock()
+		e.err = err
+		m.mu.Unlock()
+	}()
+	return e.runner, nil
+}
+
+// finished reports whether the runner name with the config hash has a
+// checkpoint that says it is done.
+func (m *Manager) finished(name, hash string) bool {
+	m.mu.Lock()
+	checkpoints := m.checkpoints
+	m.mu.Unlock()
+	if checkpoints == nil {
+		return false
+	}
+	cp, ok := checkpoints.get(name, hash)
+	return ok && cp.Done
+}
+
+// release marks a runner, or a reload, as done.
+func (m *Manager) release() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.running--
+	m.cond.Broadcast()
+}
+
+// executing reports whether the runner of e has not stopped yet.  A
+// reserved entry without a runner counts as executing.
+func (e *entry) executing() bool {
+	select {
+	case <-e.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// hashConfig returns a hash of the contents of cfg.
+func hashConfig(cfg *ucfg.Config) (string, error) {
+	var v map[string]interface{}
+	if err := cfg.Unpack(&v); err != nil {
+		return "", err
+	}
+	// encoding/json sorts map keys, so equal configs hash the same.
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Get returns the runner with the given name.
+func (m *Manager) Get(name string) (*Runner, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.byName[name]
+	if !ok || e.runner == nil {
+		return nil, fmt.Errorf("runner %s not found", name)
+	}
+	return e.runner, nil
+}
+
+// Stop cancels the runner with the given name.  The runner closes its
+// outputs in the background.
+func (m *Manager) Stop(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.byName[name]
+	if !ok || e.runner == nil {
+		return fmt.Errorf("runner %s not found", name)
+	}
+	e.cancel()
+	return nil
+}
+
+// current returns the latest runner of each name in the order they
+// were started, including those that have stopped.  It is called with
+// m.mu held.
+func (m *Manager) current() []*entry {
+	var entries []*entry
+	for _, e := range m.entries {
+		if m.byName[e.runner.name] == e {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// Runners returns the latest runner of each name in the order they
+// were started, including those that have stopped.
+func (m *Manager) Runners() []*Runner {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var runners []*Runner
+	for _, e := range m.current() {
+		runners = append(runners, e.runner)
+	}
+	return runners
+}
+
+// Info describes the latest runner of each name in the order they
+// were started.
+func (m *Manager) Info() []Info {
+	m.mu.Lock()
+	entries := m.current()
+	errs := make([]error, len(entries))
+	for i, e := range entries {
+		errs[i] = e.err
+	}
+	m.mu.Unlock()
+
+	infos := make([]Info, len(entries))
+	for i, e := range entries {
+		infos[i] = e.runner.Info()
+		if errs[i] != nil {
+			infos[i].Error = errs[i].Error()
+		}
+	}
+	return infos
+}
+
+// Wait waits for all runners to stop and returns the results of every
+// runner ever started, including replaced ones, in the order they
+// were started.
+func (m *Manager) Wait() []Result {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for m.running > 0 {
+		m.cond.Wait()
+	}
+	results := make([]Result, len(m.entries))
+	for i, e := range m.entries {
+		results[i] = Result{Runner: e.runner, Error: e.err}
+	}
+	return results
+}
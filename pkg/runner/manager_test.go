@@ -0,0 +1,225 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestConfig(c map[string]interface{}) *ucfg.Config {
+	cfg := ucfg.MustNewFrom(c)
+	_ = cfg.SetChild("generator", -1, ucfg.MustNewFrom(map[string]interface{}{"type": "aws:vpcflow"}))
+	_ = cfg.SetChild("output", -1, ucfg.MustNewFrom(map[string]interface{}{"type": testOutputName}))
+	return cfg
+}
+
+// eventually waits for cond to become true.
+func eventually(t *testing.T, cond func() bool, msg string) {
+	assert.Eventually(t, cond, time.Second, time.Millisecond, msg)
+}
+
+func TestManager(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewManager(ctx)
+
+	once, err := m.Start(newTestConfig(map[string]interface{}{"records": 3}))
+	assert.Nil(t, err)
+	assert.Equal(t, "0", once.Name())
+
+	_, err = m.Start(ucfg.MustNewFrom(map[string]interface{}{"records": 1}))
+	assert.NotNil(t, err)
+
+	forever, err := m.Start(newTestConfig(map[string]interface{}{"records": 1, "interval": "1ms"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "1", forever.Name())
+
+	eventually(t, func() bool { return once.State() == StateStopped }, "runner 0 stops")
+	eventually(t, func() bool { return forever.Stats().Records.Load() > 0 }, "runner 1 writes")
+
+	r, err := m.Get("1")
+	assert.Nil(t, err)
+	assert.Same(t, forever, r)
+	_, err = m.Get("7")
+	assert.Equal(t, "runner 7 not found", err.Error())
+
+	infos := m.Info()
+	assert.Len(t, infos, 2)
+	assert.Equal(t, StateStopped, infos[0].State)
+	assert.Equal(t, uint64(3), infos[0].Written)
+	assert.Equal(t, StateRunning, infos[1].State)
+
+	assert.Nil(t, m.Stop("1"))
+	results := m.Wait()
+	assert.Len(t, results, 2)
+	for _, res := range results {
+		assert.Nil(t, res.Error)
+		assert.Equal(t, StateStopped, res.Runner.State())
+	}
+}
+
+func TestPauseResume(t *testing.T) {
+	m := NewManager(context.Background())
+	r, err := m.Start(newTestConfig(map[string]interface{}{"records": 1, "interval": "1ms"}))
+	assert.Nil(t, err)
+	eventually(t, func() bool { return r.Stats().Records.Load() > 0 }, "runner writes")
+
+	assert.Nil(t, r.Pause())
+	assert.Equal(t, StatePaused, r.State())
+	// Let a record that was already being written finish.
+	time.Sleep(5 * time.Millisecond)
+	paused := r.Stats().Records.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, paused, r.Stats().Records.Load())
+
+	assert.Nil(t, r.Resume())
+	assert.Equal(t, StateRunning, r.State())
+	eventually(t, func() bool { return r.Stats().Records.Load() > paused }, "runner writes after resume")
+
+	// A paused runner can still be stopped.
+	assert.Nil(t, r.Pause())
+	assert.Nil(t, m.Stop(r.Name()))
+	m.Wait()
+	assert.Equal(t, StateStopped, r.State())
+	assert.Equal(t, "runner 0 has stopped", r.Resume().Error())
+}
+
+func TestTune(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		tune        map[string]interface{}
+		hasError    bool
+		errorString string
+		want        Info
+	}{
+		"Records And Interval": {
+			c:    map[string]interface{}{"records": 1, "interval": "1h"},
+			tune: map[string]interface{}{"records": 5, "interval": "2ms"},
+			want: Info{Records: 5, Interval: 2 * time.Millisecond},
+		},
+		"Add Rate": {
+			c:    map[string]interface{}{"records": 1, "interval": "1ms"},
+			tune: map[string]interface{}{"rate": map[string]interface{}{"events": 100, "bytes": 1000}},
+			want: Info{Records: 1, Interval: time.Millisecond, Events: 100, Bytes: 1000},
+		},
+		"Change Rate": {
+			c:    map[string]interface{}{"records": 1, "interval": "1ms", "rate": map[string]interface{}{"events": 10000}},
+			tune: map[string]interface{}{"rate": map[string]interface{}{"events": 200}},
+			want: Info{Records: 1, Interval: time.Millisecond, Events: 200},
+		},
+		"Bad Records": {
+			c:           map[string]interface{}{"records": 1, "interval": "1ms"},
+			tune:        map[string]interface{}{"records": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'records' expected >= 1 accessing config",
+		},
+		"Bad Rate": {
+			c:           map[string]interface{}{"records": 1, "interval": "1ms"},
+			tune:        map[string]interface{}{"rate": map[string]interface{}{"events": -1}},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'rate.events' expected a value >= 0 accessing 'rate'",
+		},
+	}
+	for name, tc := range tests {
+		m := NewManager(context.Background())
+		r, err := m.Start(newTestConfig(tc.c))
+		assert.Nil(t, err, name)
+
+		err = r.Tune(ucfg.MustNewFrom(tc.tune))
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		} else {
+			assert.Nil(t, err, name)
+			// The runner applies the change before its next record.
+			eventually(t, func() bool {
+				info := r.Info()
+				return info.Records == tc.want.Records && info.Interval == tc.want.Interval &&
+					info.Events == tc.want.Events && info.Bytes == tc.want.Bytes
+			}, name)
+		}
+		assert.Nil(t, m.Stop(r.Name()), name)
+		m.Wait()
+	}
+}
+
+func TestTuneWithoutInterval(t *testing.T) {
+	r, err := New(newTestConfig(map[string]interface{}{"records": 1}))
+	assert.Nil(t, err)
+	r.name = "0"
+	err = r.Tune(ucfg.MustNewFrom(map[string]interface{}{"interval": "1s"}))
+	assert.Equal(t, "runner 0 has no 'interval' to change", err.Error())
+}
+
+func TestTuneWithProfile(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		"Records":  {"records": 5},
+		"Interval": {"interval": "1s"},
+		"Rate":     {"rate": map[string]interface{}{"events": 200}},
+	}
+	profile := map[string]interface{}{"type": "ramp", "from": 1000, "to": 1000, "duration": "1s"}
+	for name, tune := range tests {
+		m := NewManager(context.Background())
+		r, err := m.Start(newTestConfig(map[string]interface{}{"records": 1, "interval": "1h", "profile": profile}))
+		assert.Nil(t, err, name)
+		err = r.Tune(ucfg.MustNewFrom(tune))
+		if assert.NotNil(t, err, name) {
+			assert.Equal(t, "runner "+r.Name()+" has a 'profile', 'records', 'interval' and 'rate' can not be changed", err.Error(), name)
+		}
+		assert.Nil(t, m.Stop(r.Name()), name)
+		m.Wait()
+	}
+}
+
+func TestReload(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := NewManager(ctx)
+
+	forever := map[string]interface{}{"records": 1, "interval": "1ms"}
+	faster := map[string]interface{}{"records": 2, "interval": "1ms"}
+	once := map[string]interface{}{"records": 1}
+	named := map[string]interface{}{"name": "asa", "records": 1, "interval": "1ms"}
+	get := func(name string) *Runner {
+		r, err := m.Get(name)
+		assert.Nil(t, err, name)
+		return r
+	}
+
+	assert.Nil(t, m.Reload([]*ucfg.Config{newTestConfig(forever), newTestConfig(once), newTestConfig(named)}))
+	r0, r1, asa := get("0"), get("1"), get("asa")
+	eventually(t, func() bool { return r1.State() == StateStopped }, "runner 1 finishes")
+
+	// Unchanged runners are kept, even if they have finished, changed
+	// runners are replaced and removed runners are stopped.
+	assert.Nil(t, m.Reload([]*ucfg.Config{newTestConfig(faster), newTestConfig(once)}))
+	assert.NotSame(t, r0, get("0"))
+	assert.Equal(t, StateStopped, r0.State())
+	assert.Equal(t, 2, get("0").Info().Records)
+	assert.Same(t, r1, get("1"))
+	assert.Equal(t, StateStopped, asa.State())
+	assert.Len(t, m.Runners(), 3)
+
+	// Runners started by Start are left alone, and their names
+	// cannot be taken.
+	api, err := m.Start(newTestConfig(forever))
+	assert.Nil(t, err)
+	assert.Equal(t, "2", api.Name())
+	err = m.Reload([]*ucfg.Config{newTestConfig(faster), newTestConfig(once), newTestConfig(forever)})
+	assert.Equal(t, "runner 2: runner 2 is already running", err.Error())
+	assert.NotEqual(t, StateStopped, api.State())
+
+	err = m.Reload([]*ucfg.Config{newTestConfig(named), newTestConfig(named)})
+	assert.Equal(t, "runner 1: name asa is used by another runner", err.Error())
+
+	cancel()
+	results := m.Wait()
+	assert.Len(t, results, 5)
+	for _, res := range results {
+		assert.Nil(t, res.Error)
+		assert.Equal(t, StateStopped, res.Runner.State())
+	}
+}
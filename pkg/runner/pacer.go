@@ -0,0 +1,136 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// maxLag is how far the runner may fall behind the configured
+	// rate before the output is considered unable to keep up.
+	maxLag = time.Second
+	// idle is how long to wait before asking a profile again when it
+	// asks for a rate of zero.
+	idle = 100 * time.Millisecond
+)
+
+type rateConfig struct {
+	Events float64 `config:"events"`
+	Bytes  float64 `config:"bytes"`
+}
+
+func (c *rateConfig) Validate() error {
+	if c.Events < 0 {
+		return fmt.Errorf("'%v' is not a valid value for 'rate.events' expected a value >= 0", c.Events)
+	}
+	if c.Bytes < 0 {
+		return fmt.Errorf("'%v' is not a valid value for 'rate.bytes' expected a value >= 0", c.Bytes)
+	}
+	if c.Events == 0 && c.Bytes == 0 {
+		return fmt.Errorf("rate requires 'events' or 'bytes' to be set")
+	}
+	return nil
+}
+
+// pacer spreads writes evenly over time.  It keeps a theoretical
+// arrival time for the next record and sleeps until it is reached,
+// so a record is released every 1/events seconds (or size/bytes
+// seconds when a byte rate is set, whichever is slower).  Records are
+// never released in bursts to make up for lost time; when the caller
+// falls more than maxLag behind the schedule the debt is dropped and
+// the lag is reported instead.
+//
+// When a profile is set the events rate is taken from the profile
+// before each record.
+type pacer struct {
+	events  float64
+	bytes   float64
+	profile profile
+	start   time.Time
+	next    time.Time
+	behind  bool
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+func newPacer(c rateConfig) *pacer {
+	return &pacer{
+		events: c.Events,
+		bytes:  c.Bytes,
+		now:    time.Now,
+		sleep:  sleep,
+	}
+}
+
+// sleep pauses for d or until ctx is done, whichever is first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reset restarts the schedule at the current time.  It is called at
+// the start of each interval so idle time between intervals is not
+// mistaken for lag.  Elapsed time for the profile is measured from
+// the last reset.
+func (p *pacer) reset() {
+	p.start = p.now()
+	p.next = p.start
+	p.behind = false
+}
+
+// wait blocks until a record of size bytes may be written or ctx is
+// done.  It returns true the first time the schedule slips by more
+// than maxLag, and again only after the runner has caught up in
+// between.
+func (p *pacer) wait(ctx context.Context, size int) (bool, error) {
+	now := p.now()
+	if p.profile != nil {
+		p.events = p.profile.rate(now.Sub(p.start), now)
+		for p.events <= 0 {
+			if err := p.sleep(ctx, idle); err != nil {
+				return false, err
+			}
+			now = p.now()
+			p.next = now
+			p.events = p.profile.rate(now.Sub(p.start), now)
+		}
+	}
+	lagging := false
+	if d := now.Sub(p.next); d > maxLag {
+		lagging = !p.behind
+		p.behind = true
+		p.next = now
+	} else if d <= 0 {
+		p.behind = false
+		if err := p.sleep(ctx, -d); err != nil {
+			return false, err
+		}
+	}
+	p.next = p.next.Add(p.cost(size))
+	return lagging, nil
+}
+
+// cost returns the time budget of a record of size bytes.
+func (p *pacer) cost(size int) time.Duration {
+	var secs float64
+	if p.events > 0 {
+		secs = 1 / p.events
+	}
+	if p.bytes > 0 {
+		if b := float64(size) / p.bytes; b > secs {
+			secs = b
+		}
+	}
+	return time.Duration(secs * float64(time.Second))
+}
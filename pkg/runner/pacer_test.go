@@ -0,0 +1,152 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClock struct {
+	t     time.Time
+	slept time.Duration
+}
+
+func (f *fakeClock) now() time.Time {
+	return f.t
+}
+
+func (f *fakeClock) sleep(_ context.Context, d time.Duration) error {
+	f.slept += d
+	f.t = f.t.Add(d)
+	return nil
+}
+
+func wait(t *testing.T, p *pacer, size int) bool {
+	t.Helper()
+	lagging, err := p.wait(context.Background(), size)
+	assert.Nil(t, err)
+	return lagging
+}
+
+func newTestPacer(c rateConfig) (*pacer, *fakeClock) {
+	clk := &fakeClock{t: time.Unix(0, 0)}
+	p := newPacer(c)
+	p.now = clk.now
+	p.sleep = clk.sleep
+	p.reset()
+	return p, clk
+}
+
+func TestPacer(t *testing.T) {
+	tests := map[string]struct {
+		rate    rateConfig
+		records int
+		size    int
+		want    time.Duration
+	}{
+		"Events": {
+			rate:    rateConfig{Events: 100},
+			records: 101,
+			size:    10,
+			want:    time.Second,
+		},
+		"Bytes": {
+			rate:    rateConfig{Bytes: 1000},
+			records: 11,
+			size:    100,
+			want:    time.Second,
+		},
+		"Bytes slower than events": {
+			rate:    rateConfig{Events: 1000, Bytes: 1000},
+			records: 11,
+			size:    100,
+			want:    time.Second,
+		},
+		"Events slower than bytes": {
+			rate:    rateConfig{Events: 10, Bytes: 1e6},
+			records: 11,
+			size:    100,
+			want:    time.Second,
+		},
+	}
+	for name, tc := range tests {
+		p, clk := newTestPacer(tc.rate)
+		for i := 0; i < tc.records; i++ {
+			assert.False(t, wait(t, p, tc.size), name)
+		}
+		assert.Equal(t, tc.want, clk.slept, name)
+	}
+}
+
+func TestPacerLag(t *testing.T) {
+	p, clk := newTestPacer(rateConfig{Events: 10})
+
+	assert.False(t, wait(t, p, 1))
+	clk.t = clk.t.Add(2 * time.Second)
+	assert.True(t, wait(t, p, 1), "first slip is reported")
+	clk.t = clk.t.Add(2 * time.Second)
+	assert.False(t, wait(t, p, 1), "continued slip is not reported again")
+	assert.Equal(t, time.Duration(0), clk.slept, "no catch up burst")
+
+	assert.False(t, wait(t, p, 1))
+	assert.Equal(t, 100*time.Millisecond, clk.slept)
+	clk.t = clk.t.Add(2 * time.Second)
+	assert.True(t, wait(t, p, 1), "slip after catching up is reported")
+}
+
+func TestRateConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Events": {
+			c:        map[string]interface{}{"events": 100},
+			hasError: false,
+		},
+		"Events and Bytes": {
+			c:        map[string]interface{}{"events": 100, "bytes": 1048576},
+			hasError: false,
+		},
+		"Empty": {
+			c:           map[string]interface{}{},
+			hasError:    true,
+			errorString: "rate requires 'events' or 'bytes' to be set accessing config",
+		},
+		"Negative": {
+			c:           map[string]interface{}{"events": -1},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'rate.events' expected a value >= 0 accessing config",
+		},
+	}
+	for name, tc := range tests {
+		c := rateConfig{}
+		err := ucfg.MustNewFrom(tc.c).Unpack(&c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
+
+func TestPacerProfile(t *testing.T) {
+	p, clk := newTestPacer(rateConfig{})
+	p.profile = &stepProfile{steps: []stepConfig{
+		{Rate: 0, Duration: time.Second},
+		{Rate: 10, Duration: time.Second},
+	}}
+	p.reset()
+
+	assert.False(t, wait(t, p, 1))
+	assert.Equal(t, time.Second, clk.slept, "zero rate idles until the profile allows records")
+	for i := 0; i < 10; i++ {
+		assert.False(t, wait(t, p, 1))
+	}
+	assert.Equal(t, 2*time.Second, clk.slept)
+}
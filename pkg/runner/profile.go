@@ -0,0 +1,221 @@
+package runner
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+const (
+	ProfileRamp    = "ramp"
+	ProfileStep    = "step"
+	ProfileSine    = "sine"
+	ProfileDiurnal = "diurnal"
+	ProfileBurst   = "burst"
+)
+
+var profileTypes = []string{ProfileRamp, ProfileStep, ProfileSine, ProfileDiurnal, ProfileBurst}
+
+// profile describes the target rate in events per second as a
+// function of the time elapsed since the runner started.  now is the
+// wall clock time matching elapsed.
+type profile interface {
+	rate(elapsed time.Duration, now time.Time) float64
+}
+
+type stepConfig struct {
+	Rate     float64       `config:"rate"`
+	Duration time.Duration `config:"duration" validate:"required"`
+}
+
+type profileConfig struct {
+	Type string `config:"type" validate:"required"`
+
+	// ramp
+	From     float64       `config:"from"`
+	To       float64       `config:"to"`
+	Duration time.Duration `config:"duration"`
+
+	// step
+	Steps  []stepConfig `config:"steps"`
+	Repeat bool         `config:"repeat"`
+
+	// sine and burst
+	Base float64 `config:"base"`
+
+	// sine
+	Amplitude float64       `config:"amplitude"`
+	Period    time.Duration `config:"period"`
+
+	// diurnal
+	Peak     float64 `config:"peak"`
+	Trough   float64 `config:"trough"`
+	PeakHour float64 `config:"peak_hour"`
+
+	// burst
+	BurstRate     float64       `config:"burst_rate"`
+	Probability   float64       `config:"probability"`
+	BurstDuration time.Duration `config:"burst_duration"`
+}
+
+func defaultProfileConfig() profileConfig {
+	return profileConfig{
+		PeakHour:      14,
+		BurstDuration: 10 * time.Second,
+	}
+}
+
+func (c *profileConfig) Validate() error {
+	switch c.Type {
+	case ProfileRamp:
+		if c.From < 0 || c.To < 0 {
+			return fmt.Errorf("ramp profile 'from' and 'to' must be >= 0")
+		}
+		if c.Duration <= 0 {
+			return fmt.Errorf("ramp profile requires 'duration' > 0")
+		}
+	case ProfileStep:
+		if len(c.Steps) == 0 {
+			return fmt.Errorf("step profile requires at least one entry in 'steps'")
+		}
+		for i, s := range c.Steps {
+			if s.Rate < 0 || s.Duration <= 0 {
+				return fmt.Errorf("step profile entry %d requires 'rate' >= 0 and 'duration' > 0", i)
+			}
+		}
+	case ProfileSine:
+		if c.Period <= 0 {
+			return fmt.Errorf("sine profile requires 'period' > 0")
+		}
+		if c.Amplitude < 0 || c.Base < c.Amplitude {
+			return fmt.Errorf("sine profile requires 0 <= 'amplitude' <= 'base'")
+		}
+	case ProfileDiurnal:
+		if c.Trough < 0 || c.Peak < c.Trough {
+			return fmt.Errorf("diurnal profile requires 0 <= 'trough' <= 'peak'")
+		}
+		if c.PeakHour < 0 || c.PeakHour >= 24 {
+			return fmt.Errorf("'%v' is not a valid value for 'peak_hour' expected 0 <= peak_hour < 24", c.PeakHour)
+		}
+	case ProfileBurst:
+		if c.Base < 0 || c.BurstRate < 0 {
+			return fmt.Errorf("burst profile 'base' and 'burst_rate' must be >= 0")
+		}
+		if c.Probability < 0 || c.Probability > 1 {
+			return fmt.Errorf("'%v' is not a valid value for 'probability' expected 0 <= probability <= 1", c.Probability)
+		}
+		if c.BurstDuration <= 0 {
+			return fmt.Errorf("burst profile requires 'burst_duration' > 0")
+		}
+	default:
+		return fmt.Errorf("'%s' is not a valid value for 'profile.type' expected one of %s", c.Type, strings.Join(profileTypes, ", "))
+	}
+	return nil
+}
+
+func newProfile(c profileConfig, r *rand.Rand) profile {
+	switch c.Type {
+	case ProfileRamp:
+		return &rampProfile{from: c.From, to: c.To, duration: c.Duration}
+	case ProfileStep:
+		return &stepProfile{steps: c.Steps, repeat: c.Repeat}
+	case ProfileSine:
+		return &sineProfile{base: c.Base, amplitude: c.Amplitude, period: c.Period}
+	case ProfileDiurnal:
+		return &diurnalProfile{peak: c.Peak, trough: c.Trough, peakHour: c.PeakHour}
+	case ProfileBurst:
+		return &burstProfile{base: c.Base, burstRate: c.BurstRate, probability: c.Probability, duration: c.BurstDuration, lastRoll: -1, rnd: r}
+	}
+	return nil
+}
+
+// rampProfile changes linearly from "from" to "to" over "duration"
+// and then holds at "to".
+type rampProfile struct {
+	from, to float64
+	duration time.Duration
+}
+
+func (p *rampProfile) rate(elapsed time.Duration, _ time.Time) float64 {
+	if elapsed >= p.duration {
+		return p.to
+	}
+	return p.from + (p.to-p.from)*float64(elapsed)/float64(p.duration)
+}
+
+// stepProfile holds each step's rate for the step's duration.  After
+// the last step it either starts over or holds the last rate.
+type stepProfile struct {
+	steps  []stepConfig
+	repeat bool
+}
+
+func (p *stepProfile) rate(elapsed time.Duration, _ time.Time) float64 {
+	var total time.Duration
+	for _, s := range p.steps {
+		total += s.Duration
+	}
+	if elapsed >= total {
+		if !p.repeat {
+			return p.steps[len(p.steps)-1].Rate
+		}
+		elapsed %= total
+	}
+	for _, s := range p.steps {
+		if elapsed < s.Duration {
+			return s.Rate
+		}
+		elapsed -= s.Duration
+	}
+	return p.steps[len(p.steps)-1].Rate
+}
+
+// sineProfile oscillates around "base" by "amplitude" once every
+// "period", starting at "base" and rising.
+type sineProfile struct {
+	base, amplitude float64
+	period          time.Duration
+}
+
+func (p *sineProfile) rate(elapsed time.Duration, _ time.Time) float64 {
+	return p.base + p.amplitude*math.Sin(2*math.Pi*float64(elapsed)/float64(p.period))
+}
+
+// diurnalProfile follows a 24 hour cosine curve over the local time
+// of day, reaching "peak" at "peak_hour" and "trough" twelve hours
+// later.
+type diurnalProfile struct {
+	peak, trough, peakHour float64
+}
+
+func (p *diurnalProfile) rate(_ time.Duration, now time.Time) float64 {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	hour := now.Sub(midnight).Hours()
+	return p.trough + (p.peak-p.trough)*(1+math.Cos(2*math.Pi*(hour-p.peakHour)/24))/2
+}
+
+// burstProfile runs at "base" and, once per second, starts a burst at
+// "burst_rate" lasting "burst_duration" with the given probability.
+type burstProfile struct {
+	base, burstRate, probability float64
+	duration                     time.Duration
+
+	lastRoll   time.Duration
+	burstUntil time.Duration
+	rnd        *rand.Rand
+}
+
+func (p *burstProfile) rate(elapsed time.Duration, _ time.Time) float64 {
+	if second := elapsed.Truncate(time.Second); second > p.lastRoll {
+		p.lastRoll = second
+		if elapsed >= p.burstUntil && p.rnd.Float64() < p.probability {
+			p.burstUntil = elapsed + p.duration
+		}
+	}
+	if elapsed < p.burstUntil {
+		return p.burstRate
+	}
+	return p.base
+}
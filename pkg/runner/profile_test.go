@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileRate(t *testing.T) {
+	noon := time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		c       map[string]interface{}
+		elapsed time.Duration
+		now     time.Time
+		want    float64
+	}{
+		"Ramp start": {
+			c:       map[string]interface{}{"type": "ramp", "from": 10, "to": 110, "duration": "100s"},
+			elapsed: 0,
+			want:    10,
+		},
+		"Ramp middle": {
+			c:       map[string]interface{}{"type": "ramp", "from": 10, "to": 110, "duration": "100s"},
+			elapsed: 50 * time.Second,
+			want:    60,
+		},
+		"Ramp after": {
+			c:       map[string]interface{}{"type": "ramp", "from": 10, "to": 110, "duration": "100s"},
+			elapsed: time.Hour,
+			want:    110,
+		},
+		"Ramp down": {
+			c:       map[string]interface{}{"type": "ramp", "from": 100, "to": 0, "duration": "100s"},
+			elapsed: 25 * time.Second,
+			want:    75,
+		},
+		"Step second": {
+			c: map[string]interface{}{"type": "step", "steps": []interface{}{
+				map[string]interface{}{"rate": 10, "duration": "1m"},
+				map[string]interface{}{"rate": 20, "duration": "1m"},
+			}},
+			elapsed: 90 * time.Second,
+			want:    20,
+		},
+		"Step hold": {
+			c: map[string]interface{}{"type": "step", "steps": []interface{}{
+				map[string]interface{}{"rate": 10, "duration": "1m"},
+				map[string]interface{}{"rate": 20, "duration": "1m"},
+			}},
+			elapsed: 5 * time.Minute,
+			want:    20,
+		},
+		"Step repeat": {
+			c: map[string]interface{}{"type": "step", "repeat": true, "steps": []interface{}{
+				map[string]interface{}{"rate": 10, "duration": "1m"},
+				map[string]interface{}{"rate": 20, "duration": "1m"},
+			}},
+			elapsed: 4*time.Minute + 30*time.Second,
+			want:    10,
+		},
+		"Sine start": {
+			c:       map[string]interface{}{"type": "sine", "base": 100, "amplitude": 50, "period": "1h"},
+			elapsed: 0,
+			want:    100,
+		},
+		"Sine quarter": {
+			c:       map[string]interface{}{"type": "sine", "base": 100, "amplitude": 50, "period": "1h"},
+			elapsed: 15 * time.Minute,
+			want:    150,
+		},
+		"Sine three quarters": {
+			c:       map[string]interface{}{"type": "sine", "base": 100, "amplitude": 50, "period": "1h"},
+			elapsed: 45 * time.Minute,
+			want:    50,
+		},
+		"Diurnal peak": {
+			c:    map[string]interface{}{"type": "diurnal", "peak": 1000, "trough": 100, "peak_hour": 12},
+			now:  noon,
+			want: 1000,
+		},
+		"Diurnal trough": {
+			c:    map[string]interface{}{"type": "diurnal", "peak": 1000, "trough": 100, "peak_hour": 12},
+			now:  noon.Add(12 * time.Hour),
+			want: 100,
+		},
+		"Diurnal between": {
+			c:    map[string]interface{}{"type": "diurnal", "peak": 1000, "trough": 100, "peak_hour": 12},
+			now:  noon.Add(6 * time.Hour),
+			want: 550,
+		},
+		"Burst never": {
+			c:       map[string]interface{}{"type": "burst", "base": 10, "burst_rate": 1000, "probability": 0},
+			elapsed: time.Minute,
+			want:    10,
+		},
+		"Burst always": {
+			c:       map[string]interface{}{"type": "burst", "base": 10, "burst_rate": 1000, "probability": 1},
+			elapsed: time.Minute,
+			want:    1000,
+		},
+	}
+	for name, tc := range tests {
+		c := defaultProfileConfig()
+		err := ucfg.MustNewFrom(tc.c).Unpack(&c)
+		assert.Nil(t, err, name)
+		p := newProfile(c, rand.New(rand.NewSource(1)))
+		assert.InDelta(t, tc.want, p.rate(tc.elapsed, tc.now), 1e-6, name)
+	}
+}
+
+func TestBurstProfile(t *testing.T) {
+	p := &burstProfile{base: 10, burstRate: 1000, probability: 0.1, duration: 5 * time.Second, lastRoll: -1, rnd: rand.New(rand.NewSource(1))}
+	bursting := 0
+	for s := 0; s < 1000; s++ {
+		if p.rate(time.Duration(s)*time.Second, time.Time{}) == 1000 {
+			bursting++
+		}
+	}
+	assert.Greater(t, bursting, 0)
+	assert.Less(t, bursting, 1000)
+}
+
+func TestProfileConfigs(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid Ramp": {
+			c:        map[string]interface{}{"type": "ramp", "from": 0, "to": 100, "duration": "1m"},
+			hasError: false,
+		},
+		"Ramp without duration": {
+			c:           map[string]interface{}{"type": "ramp", "to": 100},
+			hasError:    true,
+			errorString: "ramp profile requires 'duration' > 0 accessing config",
+		},
+		"Step without steps": {
+			c:           map[string]interface{}{"type": "step"},
+			hasError:    true,
+			errorString: "step profile requires at least one entry in 'steps' accessing config",
+		},
+		"Sine amplitude too large": {
+			c:           map[string]interface{}{"type": "sine", "base": 10, "amplitude": 20, "period": "1m"},
+			hasError:    true,
+			errorString: "sine profile requires 0 <= 'amplitude' <= 'base' accessing config",
+		},
+		"Diurnal bad hour": {
+			c:           map[string]interface{}{"type": "diurnal", "peak": 10, "peak_hour": 24},
+			hasError:    true,
+			errorString: "'24' is not a valid value for 'peak_hour' expected 0 <= peak_hour < 24 accessing config",
+		},
+		"Burst bad probability": {
+			c:           map[string]interface{}{"type": "burst", "base": 10, "burst_rate": 100, "probability": 2},
+			hasError:    true,
+			errorString: "'2' is not a valid value for 'probability' expected 0 <= probability <= 1 accessing config",
+		},
+		"Invalid Type": {
+			c:           map[string]interface{}{"type": "square"},
+			hasError:    true,
+			errorString: "'square' is not a valid value for 'profile.type' expected one of ramp, step, sine, diurnal, burst accessing config",
+		},
+	}
+	for name, tc := range tests {
+		c := defaultProfileConfig()
+		err := ucfg.MustNewFrom(tc.c).Unpack(&c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
@@ -2,8 +2,37 @@
 //
 //	Configuration.
 //
-//	"generator" and "output" are required, and are the configs of the
-//	specific types.
+//	"name" is optional and names the runner in the control API, the
+//	metrics and when the config is reloaded.  It defaults to the
+//	runner's position in the list of runners, starting at "0".
+//
+//	"generator" is required, and is the config of the specific type.
+//
+//	"output" or "outputs" is required.  "output" is the config of the
+//	specific output type.  "outputs" is a list of such configs, and
+//	every generated record is written to each of them in order.
+//
+//	"on_error" is optional, default is "fail".  It is the policy for
+//	failed writes to an output, and may also be set in an output's
+//	config to override it for that output:
+//
+//	  fail:       the runner stops with the error.
+//	  skip, drop: the error is logged the first time, counted, and the
+//	              record is dropped for that output only.
+//	  retry:      the write is attempted again with exponential
+//	              backoff.
+//	  reconnect:  like retry, but the output is closed and created
+//	              again from its config before each new attempt, for
+//	              example to re-establish a syslog TCP connection.
+//
+//	"retry" is optional and configures the retry and reconnect
+//	policies.  It may also be set in an output's config.
+//	"max_attempts" is the number of attempts including the first,
+//	default is 5, 0 means no limit; once it is reached the runner
+//	stops with the error.  The wait between attempts starts at
+//	"initial_backoff", default is 100ms, and doubles up to
+//	"max_backoff", default is 30s.  Write errors, retries, reconnects
+//	and dropped records are counted.
 //
 //	"records" is optional, default is 1024.  This is the number of log
 //	records to write per interval.
@@ -12,6 +41,116 @@
 //	given then the runner is executed once.  If an interval is given
 //	then at each interval the runner is executed.
 //
+//	"schedule" is optional and is a cron expression with the fields
+//	minute, hour, day of month, month and day of week, such as
+//	"*/5 9-17 * * 1-5" for every 5 minutes during business hours on
+//	weekdays.  It replaces "interval": the runner waits for the
+//	schedule to fire, in local time, writes "records" records and
+//	calls NewInterval on the outputs, until it is stopped.  Months and
+//	days may be names such as "jan" or "mon", and @hourly, @daily,
+//	@weekly, @monthly and @yearly are accepted too.  It may not be
+//	combined with "interval" or "profile".
+//
+//	"rate" is optional.  Without it the records for an interval are
+//	written back-to-back as fast as the output accepts them.  With it
+//	the writes are spread evenly at "events" records per second and/or
+//	"bytes" bytes per second, whichever is slower.  "records" and
+//	"interval" keep their meaning, so to write continuously at a rate
+//	set "records" to the number of records produced in one "interval"
+//	at that rate.  If the output cannot keep up with the rate this is
+//	logged and counted, and the runner continues as fast as it can.
+//
+//	"profile" is optional and makes the events rate change over time.
+//	With a profile the runner writes continuously at the rate the
+//	profile gives for the time elapsed since it started, "records" is
+//	ignored and "interval" only controls how often NewInterval is
+//	called on the output.  A "rate" with "bytes" may be combined with a
+//	profile as an upper bound.  "type" selects the shape:
+//
+//	  ramp:    linear from "from" to "to" events/s over "duration",
+//	           then holds at "to".
+//	  step:    a list of "steps", each a "rate" held for "duration".
+//	           Holds the last rate afterwards unless "repeat" is true.
+//	  sine:    "base" +/- "amplitude" events/s with the given "period".
+//	  diurnal: 24h curve over the local time of day between "trough"
+//	           and "peak" events/s, peaking at "peak_hour" (default 14).
+//	  burst:   "base" events/s, and every second a burst of
+//	           "burst_rate" events/s for "burst_duration" (default 10s)
+//	           starts with the given "probability".
+//
+//	"max_records", "max_bytes" and "duration" are optional and stop
+//	the runner once that many records or bytes of generated records
+//	have been written, or once the go duration has passed since it
+//	started, whichever comes first.  "max_bytes" counts the records
+//	without delimiters and is never exceeded; the record that would
+//	exceed it is not written.  When a limit is reached the output is
+//	closed, so an interval runner or a profile ends cleanly instead of
+//	running forever.
+//
+//	"seed" is optional.  If given, the generator draws all its random
+//	values from a source seeded with it, so the same config and seed
+//	produce identical records regardless of other runners.  If not
+//	given the source is seeded from the global math/rand source, which
+//	is seeded from the current time with the "-r" flag.  Timestamps
+//	are not affected by the seed.
+//
+//	"time" is optional.  Without it records are dated with the
+//	current time.  With it records are dated by a virtual clock, for
+//	example to backfill logs from the past as fast as the outputs
+//	accept them.  "start" is required and is when the clock starts,
+//	either an RFC 3339 timestamp or a go duration relative to when the
+//	config is loaded, such as "-168h" for a week ago.  "end" is
+//	optional, in the same format, and stops the runner like
+//	"max_records" once the clock reaches it.  One of "speed", how many
+//	times faster than real time the clock runs, default is 1, or
+//	"step", a go duration the clock advances by for every record, may
+//	be set.  With "step" timestamps do not depend on how fast records
+//	are written, so they are reproducible with a "seed".
+//
+//	"disorder" is optional.  It makes records arrive the way they do
+//	from real sources, late, out of order or more than once, for any
+//	generator.  "late" dates "fraction" of the records back by a
+//	random "delay", a go duration, drawn from "distribution", either
+//	"uniform", the default, for a delay between 0 and "delay", or
+//	"exponential" for a mean delay of "delay".  Only the timestamps
+//	the generator takes from its clock are moved, so this works with
+//	"time".  "reorder" holds back "window" records and writes a random
+//	one of them each time a record is written.  "duplicate" writes
+//	"fraction" of the records twice; with "reorder" the copy is
+//	written at a random point in the window.  Duplicates count towards
+//	"records" and "max_records".  The disorder has its own source, so
+//	with a "seed" the records are reproducible too.
+//
+//	"workers" is optional, default is 1.  With more than one worker
+//	that many instances of the generator produce records concurrently
+//	into a queue of "queue_size" records, default is 1024, and a single
+//	writer takes records from the queue, paces them and writes them to
+//	the outputs.  Records of one worker are written in the order that
+//	worker generated them, but records of different workers are
+//	interleaved in no particular order, so with more than one worker
+//	the output is not reproducible even with a "seed".  Each worker's
+//	generator is seeded from the runner's source.  Records still in the
+//	queue when the runner stops are discarded.  Workers only help when
+//	generating records, rather than writing them, is the bottleneck.
+//
+//	When spigot is given a state file a runner saves a checkpoint of
+//	its progress at every interval and when it stops, and a runner
+//	with the same name and config resumes from it.  The records and
+//	bytes written and the time elapsed carry on, so limits include
+//	what was written before, as does a "time" clock.  Outputs that
+//	write files continue the file they were writing, dropping anything
+//	written after the checkpoint.  With a "seed" the random sources
+//	of the generators continue from the same number of values drawn,
+//	so the records do not repeat those already written, but they are
+//	not the same as those of a runner that was never stopped.
+//	Records held back by "disorder" are lost, and a "profile" starts
+//	again.
+//
+//	"drain_timeout" is optional, default is 30s.  This is how long
+//	closing the output, which may flush buffered records, is allowed
+//	to take when the runner stops, either because it is done, a limit
+//	was reached or it was cancelled.
+//
 //	Example:
 //
 //	  generator:
@@ -26,41 +165,314 @@
 //
 //	This would write 2 vpcflow log entries to a file in the
 //	/var/tmp/spigot_asa_<random>.log file every 5 seconds.
+//
+//	  generator:
+//	    type: "cisco:asa"
+//	  output:
+//	    type: syslog
+//	    network: udp
+//	    host: localhost
+//	    port: 514
+//	  interval: 10s
+//	  records: 5000
+//	  rate:
+//	    events: 500
+//
+//	This would send 500 asa log entries per second, one every 2ms,
+//	instead of 5000 entries at once every 10 seconds.
+//
+//	  generator:
+//	    type: "cisco:asa"
+//	  output:
+//	    type: syslog
+//	    network: udp
+//	    host: localhost
+//	    port: 514
+//	  profile:
+//	    type: ramp
+//	    from: 10
+//	    to: 1000
+//	    duration: 10m
+//
+//	This would start at 10 asa log entries per second and increase
+//	steadily to 1000 per second over 10 minutes.
+//
+//	  generator:
+//	    type: "aws:vpcflow"
+//	  output:
+//	    type: file
+//	    filename: "/var/tmp/vpcflow.log"
+//	    delimiter: "\n"
+//	  interval: 1s
+//	  records: 100
+//	  max_records: 1000000
+//
+//	This would write exactly one million vpcflow log entries, 100 per
+//	second, and then exit.
+//
+//	  generator:
+//	    type: "cisco:asa"
+//	  outputs:
+//	    - type: syslog
+//	      network: tcp
+//	      host: localhost
+//	      port: 514
+//	      on_error: drop
+//	    - type: file
+//	      filename: "/var/tmp/asa.log"
+//	      delimiter: "\n"
+//	  interval: 1s
+//	  records: 100
+//
+//	This would send 100 asa log entries per second to syslog and save
+//	the same entries to a file, even while syslog is unreachable.
+//
+//	  generator:
+//	    type: "cisco:asa"
+//	  output:
+//	    type: syslog
+//	    network: tcp
+//	    host: localhost
+//	    port: 514
+//	  on_error: reconnect
+//	  retry:
+//	    max_attempts: 0
+//	    max_backoff: 1m
+//	  interval: 1s
+//	  records: 100
+//
+//	This would keep sending asa log entries to syslog, reconnecting
+//	whenever the connection is reset, for as long as it takes.
+//
+//	  generator:
+//	    type: clf
+//	  output:
+//	    type: file
+//	    filename: "/var/tmp/access.log"
+//	    delimiter: "\n"
+//	  interval: 1ms
+//	  records: 1000
+//	  time:
+//	    start: "-168h"
+//	    end: "0s"
+//	    step: 100ms
+//
+//	This would write a week of access log entries, 10 per second of
+//	log time dated from a week ago until now, as fast as the file can
+//	be written, and then exit.
+//
+//	  generator:
+//	    type: "cisco:asa"
+//	  output:
+//	    type: syslog
+//	    network: udp
+//	    host: localhost
+//	    port: 514
+//	  interval: 1s
+//	  records: 100
+//	  disorder:
+//	    late:
+//	      fraction: 0.05
+//	      delay: 10m
+//	      distribution: exponential
+//	    reorder:
+//	      window: 50
+//	    duplicate:
+//	      fraction: 0.01
+//
+//	This would send 100 asa log entries per second, 5% of them dated
+//	on average 10 minutes in the past, shuffled within groups of 50,
+//	with 1% of them sent twice.
+//
+//	  generator:
+//	    type: clf
+//	  output:
+//	    type: file
+//	    filename: "/var/tmp/access.log"
+//	    delimiter: "\n"
+//	  schedule: "*/5 9-17 * * 1-5"
+//	  records: 500
+//	  rate:
+//	    events: 10
+//
+//	This would write 500 access log entries, 10 per second, every 5
+//	minutes from 9:00 to 17:55 on weekdays.
 package runner
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/clock"
 	"github.com/leehinman/spigot/pkg/generator"
 	_ "github.com/leehinman/spigot/pkg/include"
 	"github.com/leehinman/spigot/pkg/output"
 )
 
-// Runner holds the config, output and generator.
+// Runner holds the config, outputs and generator.
 type Runner struct {
-	config    config
-	generator generator.Generator
-	output    output.Output
+	config        config
+	generatorType string
+	generators    []generator.Generator
+	queue         chan record
+	sinks         []*sink
+	pacer         *pacer
+	stats         *Stats
+	ctl           *control
+	name          string
+	// clock dates the records if the runner has a "time" block.
+	clock *clock.Virtual
+	// disorder reorders and duplicates records if the runner has a
+	// "disorder" block.
+	disorder *disorder
+	// checkpoints is where the runner saves its progress if it has a
+	// state file, and hash is the hash of its config.  sources are
+	// the sources of its generators if it also has a "seed", and
+	// resumed is how long it ran before it was resumed.
+	checkpoints *Checkpoints
+	hash        string
+	sources     []*countingSource
+	resumed     time.Duration
+}
+
+// Stats holds the counters of a runner.  They are updated atomically
+// and may be read while the runner is executing.
+type Stats struct {
+	// Records is the number of records written.
+	Records atomic.Uint64
+	// Bytes is the number of bytes of generated records written.
+	Bytes atomic.Uint64
+	// Lagged is the number of times the output fell behind the
+	// configured rate.
+	Lagged atomic.Uint64
+	// WriteErrors is the number of failed writes to an output,
+	// including failed retries and reconnects.
+	WriteErrors atomic.Uint64
+	// Retries is the number of writes attempted again because of the
+	// "retry" or "reconnect" error policy.
+	Retries atomic.Uint64
+	// Reconnects is the number of times an output was created again
+	// because of the "reconnect" error policy.
+	Reconnects atomic.Uint64
+	// Dropped is the number of records not written to an output
+	// because of the "skip" or "drop" error policy.
+	Dropped atomic.Uint64
+	// WriteLatency is the time taken to write each record to the
+	// outputs, including retries.
+	WriteLatency Histogram
+
+	// rate holds the float64 bits of the configured events rate.
+	rate atomic.Uint64
+}
+
+// Rate returns the events per second the runner is configured to
+// write at, following the profile if there is one.  It is 0 if the
+// runner is not paced by events.
+func (s *Stats) Rate() float64 {
+	return math.Float64frombits(s.rate.Load())
+}
+
+func (s *Stats) setRate(rate float64) {
+	s.rate.Store(math.Float64bits(rate))
 }
 
 type config struct {
-	Generator *ucfg.Config  `config:"generator" validate:"required"`
-	Output    *ucfg.Config  `config:"output" validate:"required"`
-	Interval  time.Duration `config:"interval"`
-	Records   int           `config:"records"`
+	Generator    *ucfg.Config    `config:"generator" validate:"required"`
+	Output       *ucfg.Config    `config:"output"`
+	Outputs      []*ucfg.Config  `config:"outputs"`
+	OnError      string          `config:"on_error"`
+	Retry        retryConfig     `config:"retry"`
+	Interval     time.Duration   `config:"interval"`
+	Schedule     *schedule       `config:"schedule"`
+	Records      int             `config:"records"`
+	Rate         *rateConfig     `config:"rate"`
+	Profile      *ucfg.Config    `config:"profile"`
+	DrainTimeout time.Duration   `config:"drain_timeout"`
+	Seed         *int64          `config:"seed"`
+	Time         *timeConfig     `config:"time"`
+	Disorder     *disorderConfig `config:"disorder"`
+	Workers      int             `config:"workers"`
+	QueueSize    int             `config:"queue_size"`
+
+	MaxRecords uint64        `config:"max_records"`
+	MaxBytes   uint64        `config:"max_bytes"`
+	Duration   time.Duration `config:"duration"`
+}
+
+func (c *config) Validate() error {
+	if c.Output == nil && len(c.Outputs) == 0 {
+		return fmt.Errorf("one of 'output' or 'outputs' is required")
+	}
+	if c.Output != nil && len(c.Outputs) > 0 {
+		return fmt.Errorf("only one of 'output' or 'outputs' may be set")
+	}
+	if c.Schedule != nil && c.Interval > 0 {
+		return fmt.Errorf("only one of 'schedule' or 'interval' may be set")
+	}
+	if c.Schedule != nil && c.Profile != nil {
+		return fmt.Errorf("only one of 'schedule' or 'profile' may be set")
+	}
+	if err := validateOnError(c.OnError); err != nil {
+		return err
+	}
+	if c.Records < 1 {
+		return fmt.Errorf("'%d' is not a valid value for 'records' expected >= 1", c.Records)
+	}
+	if c.Workers < 1 {
+		return fmt.Errorf("'%d' is not a valid value for 'workers' expected >= 1", c.Workers)
+	}
+	if c.QueueSize < 1 {
+		return fmt.Errorf("'%d' is not a valid value for 'queue_size' expected >= 1", c.QueueSize)
+	}
+	return nil
+}
+
+// record is a generated record, or the error generating it, passed
+// from a worker to the writer.
+type record struct {
+	b   []byte
+	err error
 }
 
+type typeConfig struct {
+	Type string `config:"type"`
+}
+
+// errLimit is returned by writeNext when one of the configured limits
+// has been reached.
+var errLimit = errors.New("limit reached")
+
 func defaultConfig() config {
 	c := config{
-		Records: 1024,
+		Records:      1024,
+		DrainTimeout: 30 * time.Second,
+		Workers:      1,
+		QueueSize:    1024,
+		OnError:      OnErrorFail,
+		Retry:        defaultRetryConfig(),
 	}
 	return c
 }
 
 // New is Factory for creating a new runner
 func New(cfg *ucfg.Config) (Runner, error) {
-	r := Runner{}
+	return newRunner(cfg, nil, "", "")
+}
+
+// newRunner creates a runner named name that saves its progress to
+// checkpoints, if it is not nil, and continues from the checkpoint of
+// the same name and config hash if there is one.
+func newRunner(cfg *ucfg.Config, checkpoints *Checkpoints, name, hash string) (Runner, error) {
+	r := Runner{stats: &Stats{}, ctl: &control{state: StateCreated}, name: name, checkpoints: checkpoints, hash: hash}
 	c := defaultConfig()
 	err := cfg.Unpack(&c)
 	if err != nil {
@@ -69,46 +481,530 @@ func New(cfg *ucfg.Config) (Runner, error) {
 
 	r.config = c
 
-	o, err := output.New(c.Output)
-	if err != nil {
-		return r, err
+	var resume *Checkpoint
+	if checkpoints != nil {
+		if cp, ok := checkpoints.get(name, hash); ok {
+			resume = &cp
+			r.stats.Records.Store(cp.Records)
+			r.stats.Bytes.Store(cp.Bytes)
+			r.resumed = cp.Elapsed
+		}
 	}
 
-	r.output = o
+	seed := rand.Int63()
+	if c.Seed != nil {
+		seed = *c.Seed
+	}
+	// With a state file the values drawn from the sources of the
+	// generators are counted, so that a resumed runner carries on
+	// from the same state instead of repeating its records.
+	newSource := rand.NewSource
+	if checkpoints != nil && c.Seed != nil {
+		newSource = func(seed int64) rand.Source {
+			var draws uint64
+			if resume != nil && len(r.sources) < len(resume.Draws) {
+				draws = resume.Draws[len(r.sources)]
+			}
+			s := newCountingSource(seed, draws)
+			r.sources = append(r.sources, s)
+			return s
+		}
+	}
 
-	g, err := generator.New(c.Generator)
-	if err != nil {
-		return r, err
+	clk := clock.Real
+	if c.Time != nil {
+		speed := c.Time.Speed
+		if speed == 0 && c.Time.Step == 0 {
+			speed = 1
+		}
+		start := c.Time.Start.Time
+		if resume != nil && resume.Time != nil {
+			start = *resume.Time
+		}
+		r.clock = clock.NewVirtual(start, speed, c.Time.Step)
+		clk = r.clock
+	}
+
+	var t typeConfig
+	if err := c.Generator.Unpack(&t); err == nil {
+		r.generatorType = t.Type
+	}
+
+	if c.Rate != nil {
+		r.pacer = newPacer(*c.Rate)
+		r.stats.setRate(c.Rate.Events)
+	}
+
+	if c.Profile != nil {
+		pc := defaultProfileConfig()
+		if err := c.Profile.Unpack(&pc); err != nil {
+			return r, err
+		}
+		if r.pacer == nil {
+			r.pacer = newPacer(rateConfig{})
+		}
+		// The profile gets its own source so that its draws, which
+		// depend on timing, do not change the generated records.
+		r.pacer.profile = newProfile(pc, rand.New(rand.NewSource(seed+1)))
+	}
+
+	// The disorder gets its own source too, so that records are the
+	// same with and without it, apart from their order and timestamps.
+	newGenerator := func(gr *rand.Rand) (generator.Generator, error) {
+		return generator.New(c.Generator, gr, clk)
+	}
+	if c.Disorder != nil {
+		drnd := rand.New(rand.NewSource(seed + 2))
+		r.disorder = newDisorder(*c.Disorder, rand.New(rand.NewSource(drnd.Int63())))
+		if c.Disorder.Late != nil {
+			newGenerator = func(gr *rand.Rand) (generator.Generator, error) {
+				return newLateGenerator(c.Generator, gr, clk, c.Disorder.Late, rand.New(rand.NewSource(drnd.Int63())))
+			}
+		}
+	}
+
+	if c.Workers == 1 {
+		g, err := newGenerator(rand.New(newSource(seed)))
+		if err != nil {
+			return r, err
+		}
+		r.generators = []generator.Generator{g}
+	} else {
+		rnd := rand.New(rand.NewSource(seed))
+		for i := 0; i < c.Workers; i++ {
+			g, err := newGenerator(rand.New(newSource(rnd.Int63())))
+			if err != nil {
+				return r, err
+			}
+			r.generators = append(r.generators, g)
+		}
+	}
+
+	outputs := c.Outputs
+	if c.Output != nil {
+		outputs = []*ucfg.Config{c.Output}
+	}
+	for i, oc := range outputs {
+		var s *sink
+		if p := resume.output(i); p != nil {
+			s, err = newResumedSink(oc, sinkConfig{OnError: c.OnError, Retry: c.Retry}, r.stats, *p)
+		} else {
+			s, err = newSink(oc, sinkConfig{OnError: c.OnError, Retry: c.Retry}, r.stats)
+		}
+		if err != nil {
+			// Close the outputs that were already opened.
+			return r, errors.Join(err, r.closeOutputs())
+		}
+		r.sinks = append(r.sinks, s)
 	}
-	r.generator = g
 
 	return r, nil
 }
 
-// Execute runs the runner
-func (r *Runner) Execute() error {
-	var ticker *time.Ticker = nil
-	if r.config.Interval > 0 {
-		ticker = time.NewTicker(r.config.Interval)
+// Validate checks cfg the way New does, including the configs of the
+// generator, the profile and every output, without opening any
+// output.  Errors that do not carry the path of the setting in the
+// config are prefixed with it.
+func Validate(cfg *ucfg.Config) error {
+	var errs []error
+	c := defaultConfig()
+	if err := cfg.Unpack(&c); err != nil {
+		errs = append(errs, err)
+		// Carry on with the parts of the runner, so that their errors
+		// are reported too.
+		c = defaultConfig()
+		var p partsConfig
+		if err := cfg.Unpack(&p); err != nil {
+			return errors.Join(errs...)
+		}
+		c.Generator, c.Output, c.Outputs, c.Profile = p.Generator, p.Output, p.Outputs, p.Profile
 	}
 
-	for ; true; <-ticker.C {
+	if c.Profile != nil {
+		pc := defaultProfileConfig()
+		if err := c.Profile.Unpack(&pc); err != nil {
+			errs = append(errs, withPath(c.Profile, err))
+		}
+	}
+	if c.Generator != nil {
+		if _, err := generator.New(c.Generator, rand.New(rand.NewSource(0)), clock.Real); err != nil {
+			errs = append(errs, withPath(c.Generator, err))
+		}
+	}
+
+	outputs := c.Outputs
+	if c.Output != nil {
+		outputs = []*ucfg.Config{c.Output}
+	}
+	for _, oc := range outputs {
+		sc := sinkConfig{OnError: c.OnError, Retry: c.Retry}
+		if err := oc.Unpack(&sc); err != nil {
+			errs = append(errs, withPath(oc, err))
+			continue
+		}
+		if err := output.Validate(oc); err != nil {
+			errs = append(errs, withPath(oc, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// partsConfig holds the configs of the parts of a runner, without the
+// validation of config.
+type partsConfig struct {
+	Generator *ucfg.Config   `config:"generator"`
+	Output    *ucfg.Config   `config:"output"`
+	Outputs   []*ucfg.Config `config:"outputs"`
+	Profile   *ucfg.Config   `config:"profile"`
+}
+
+// withPath prefixes err with the path of cfg, unless err comes from
+// ucfg, which already includes it.
+func withPath(cfg *ucfg.Config, err error) error {
+	var uerr ucfg.Error
+	if errors.As(err, &uerr) {
+		return err
+	}
+	return fmt.Errorf("%s: %w", cfg.Path("."), err)
+}
+
+// Stats returns the counters of the runner.
+func (r *Runner) Stats() *Stats {
+	return r.stats
+}
+
+// GeneratorType returns the type of the runner's generator.
+func (r *Runner) GeneratorType() string {
+	return r.generatorType
+}
+
+// OutputTypes returns the types of the runner's outputs.
+func (r *Runner) OutputTypes() []string {
+	types := make([]string, len(r.sinks))
+	for i, s := range r.sinks {
+		types[i] = s.typ
+	}
+	return types
+}
+
+// String describes the runner by its generator and output types.
+func (r *Runner) String() string {
+	return fmt.Sprintf("%s -> %s", r.generatorType, strings.Join(r.OutputTypes(), ", "))
+}
+
+// Elapsed returns how long the runner has been executing, or how long
+// it executed if it has stopped.
+func (r *Runner) Elapsed() time.Duration {
+	r.ctl.mu.Lock()
+	defer r.ctl.mu.Unlock()
+	return r.elapsed()
+}
+
+func (r *Runner) elapsed() time.Duration {
+	if r.ctl.start.IsZero() {
+		return 0
+	}
+	if r.ctl.stop.IsZero() {
+		return time.Since(r.ctl.start)
+	}
+	return r.ctl.stop.Sub(r.ctl.start)
+}
+
+// Execute runs the runner until it is done, a limit is reached or ctx
+// is cancelled.  Cancelling ctx is not an error; in every case the
+// outputs are closed, waiting at most drain_timeout for them.
+func (r *Runner) Execute(ctx context.Context) error {
+	r.setState(StateRunning)
+	defer r.setState(StateStopped)
+	parent := ctx
+
+	if r.clock != nil {
+		r.clock.Start()
+	}
+
+	if r.config.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.config.Duration-r.resumed)
+		defer cancel()
+	}
+
+	if len(r.generators) > 1 {
+		wctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		r.queue = make(chan record, r.config.QueueSize)
+		for _, g := range r.generators {
+			wg.Add(1)
+			go func(g generator.Generator) {
+				defer wg.Done()
+				r.generate(wctx, g)
+			}(g)
+		}
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+	}
+
+	var err error
+	switch {
+	case r.pacer != nil && r.pacer.profile != nil:
+		err = r.executeProfile(ctx)
+	case r.config.Schedule != nil:
+		err = r.executeSchedule(ctx)
+	default:
+		err = r.executeIntervals(ctx)
+	}
+	if errors.Is(err, errLimit) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		err = nil
+	}
+	err = errors.Join(err, r.closeOutputs())
+	r.checkpoint(err == nil && parent.Err() == nil)
+	return err
+}
+
+// executeIntervals writes "records" records per interval, or once if
+// there is no interval.
+func (r *Runner) executeIntervals(ctx context.Context) error {
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	interval := r.config.Interval
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		if r.pacer != nil {
+			r.pacer.reset()
+		}
 		for i := 0; i < r.config.Records; i++ {
-			b, err := r.generator.Next()
-			if err != nil {
+			if err := r.writeNext(ctx); err != nil {
 				return err
 			}
-			_, err = r.output.Write(b)
-			if err != nil {
+		}
+		if r.config.Interval == 0 {
+			return nil
+		}
+		if r.limitReached() {
+			return errLimit
+		}
+		if err := r.newInterval(ctx); err != nil {
+			return err
+		}
+		if r.config.Interval != interval {
+			interval = r.config.Interval
+			ticker.Reset(interval)
+		}
+		select {
+		case <-tick:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// executeSchedule writes "records" records every time the schedule
+// fires, in local time, and then starts a new interval on the
+// outputs.
+func (r *Runner) executeSchedule(ctx context.Context) error {
+	for {
+		timer := time.NewTimer(time.Until(r.config.Schedule.next(time.Now())))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+		if r.pacer != nil {
+			r.pacer.reset()
+		}
+		for i := 0; i < r.config.Records; i++ {
+			if err := r.writeNext(ctx); err != nil {
 				return err
 			}
 		}
-		if r.config.Interval == 0 {
-			break
+		if r.limitReached() {
+			return errLimit
+		}
+		if err := r.newInterval(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// executeProfile writes continuously at the rate given by the
+// profile, calling NewInterval on the output at each interval.
+func (r *Runner) executeProfile(ctx context.Context) error {
+	var tick <-chan time.Time
+	if r.config.Interval > 0 {
+		ticker := time.NewTicker(r.config.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	r.pacer.reset()
+	for {
+		select {
+		case <-tick:
+			if err := r.newInterval(ctx); err != nil {
+				return err
+			}
+		default:
+		}
+		if err := r.writeNext(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// writeNext generates the next record, waits for the pacer if there
+// is one, and writes the record to each output.  It returns errLimit
+// instead of writing if a limit has been reached, and the context's
+// error if ctx is done.
+func (r *Runner) writeNext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := r.checkControl(ctx); err != nil {
+		return err
+	}
+	if r.limitReached() {
+		return errLimit
+	}
+	var b []byte
+	var err error
+	if r.disorder != nil {
+		b, err = r.disorder.next(ctx, r.next)
+	} else {
+		b, err = r.next(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	if r.config.MaxBytes > 0 && r.stats.Bytes.Load()+uint64(len(b)) > r.config.MaxBytes {
+		return errLimit
+	}
+	if r.pacer != nil {
+		lagging, err := r.pacer.wait(ctx, len(b))
+		if err != nil {
+			return err
 		}
-		if err := r.output.NewInterval(); err != nil {
+		if lagging {
+			r.stats.Lagged.Add(1)
+			log.Printf("runner: output cannot keep up with rate of %v events/s, %v bytes/s", r.pacer.events, r.pacer.bytes)
+		}
+		r.stats.setRate(r.pacer.events)
+	}
+	start := time.Now()
+	for _, s := range r.sinks {
+		if err := s.write(ctx, b); err != nil {
 			return err
 		}
 	}
-	return r.output.Close()
+	r.stats.WriteLatency.Observe(time.Since(start))
+	r.stats.Records.Add(1)
+	r.stats.Bytes.Add(uint64(len(b)))
+	return nil
+}
+
+// next returns the next record, either from the generator or, with
+// more than one worker, from the queue.
+func (r *Runner) next(ctx context.Context) ([]byte, error) {
+	if r.queue == nil {
+		r.tick()
+		return r.generators[0].Next()
+	}
+	select {
+	case rec := <-r.queue:
+		return rec.b, rec.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// generate is run by each worker and puts records from g on the queue
+// until ctx is done or g fails.
+func (r *Runner) generate(ctx context.Context, g generator.Generator) {
+	for {
+		r.tick()
+		b, err := g.Next()
+		if err == nil {
+			// Generators may reuse their buffer on the next call.
+			b = append([]byte(nil), b...)
+		}
+		select {
+		case r.queue <- record{b: b, err: err}:
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// limitReached reports whether max_records, max_bytes or the end of
+// the "time" block has been reached.  duration is handled by the
+// context passed to Execute.
+func (r *Runner) limitReached() bool {
+	c := r.config
+	return (c.MaxRecords > 0 && r.stats.Records.Load() >= c.MaxRecords) ||
+		(c.MaxBytes > 0 && r.stats.Bytes.Load() >= c.MaxBytes) ||
+		(c.Time != nil && c.Time.End != nil && !r.clock.Now().Before(c.Time.End.Time))
+}
+
+// tick advances the clock of a runner with a "time" block before each
+// record is generated.
+func (r *Runner) tick() {
+	if r.clock != nil {
+		r.clock.Tick()
+	}
+}
+
+// newInterval starts a new interval on each output.
+func (r *Runner) newInterval(ctx context.Context) error {
+	for _, s := range r.sinks {
+		if err := s.newInterval(ctx); err != nil {
+			return err
+		}
+	}
+	r.checkpoint(false)
+	return nil
+}
+
+// closeOutputs closes the outputs concurrently, giving up on those
+// that take longer than drain_timeout.
+func (r *Runner) closeOutputs() error {
+	errs := make([]error, len(r.sinks))
+	var wg sync.WaitGroup
+	for i, s := range r.sinks {
+		wg.Add(1)
+		go func(i int, s *sink) {
+			defer wg.Done()
+			errs[i] = r.closeOutput(s)
+		}(i, s)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// closeOutput closes the output of s, giving up after drain_timeout.
+// An output already closed by a failed reconnect is left alone.
+func (r *Runner) closeOutput(s *sink) error {
+	if s.closed {
+		return nil
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- s.output.Close()
+	}()
+	timer := time.NewTimer(r.config.DrainTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("closing output %s timed out after %v", s.typ, r.config.DrainTimeout)
+	}
 }
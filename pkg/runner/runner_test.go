@@ -0,0 +1,624 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testOutputName  = "runner:test"
+	failOutputName  = "runner:fail"
+	flakyOutputName = "runner:flaky"
+)
+
+// testOutput records everything written to it.
+type testOutput struct {
+	records   [][]byte
+	intervals int
+	closed    bool
+}
+
+var lastTestOutput *testOutput
+
+func init() {
+	output.Register(testOutputName, func(*ucfg.Config) (output.Output, error) {
+		lastTestOutput = &testOutput{}
+		return lastTestOutput, nil
+	})
+	output.Register(failOutputName, func(*ucfg.Config) (output.Output, error) {
+		return &failOutput{}, nil
+	})
+	output.Register(flakyOutputName, func(*ucfg.Config) (output.Output, error) {
+		flakyOutputs++
+		lastTestOutput = &testOutput{}
+		return &flakyOutput{testOutput: lastTestOutput, instance: flakyOutputs}, nil
+	})
+}
+
+// flakyFailures is the number of writes the flaky outputs fail before
+// they start to succeed.  If flakyPerInstance is set, only writes to
+// the first instance fail, as if a connection had been reset.
+var (
+	flakyFailures    int
+	flakyPerInstance bool
+	flakyOutputs     int
+)
+
+type flakyOutput struct {
+	*testOutput
+	instance int
+}
+
+func (o *flakyOutput) Write(b []byte) (int, error) {
+	if flakyPerInstance && o.instance > 1 {
+		return o.testOutput.Write(b)
+	}
+	if flakyFailures > 0 {
+		flakyFailures--
+		return 0, errors.New("connection reset")
+	}
+	return o.testOutput.Write(b)
+}
+
+// failOutput fails every write.
+type failOutput struct {
+	testOutput
+}
+
+func (o *failOutput) Write(b []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func (o *testOutput) Write(b []byte) (int, error) {
+	o.records = append(o.records, append([]byte(nil), b...))
+	return len(b), nil
+}
+
+func (o *testOutput) Close() error {
+	o.closed = true
+	return nil
+}
+
+func (o *testOutput) NewInterval() error {
+	o.intervals++
+	return nil
+}
+
+func TestExecute(t *testing.T) {
+	tests := map[string]struct {
+		c         map[string]interface{}
+		records   int
+		intervals int
+		maxBytes  uint64
+		minTime   time.Duration
+	}{
+		"Once": {
+			c: map[string]interface{}{
+				"records": 10,
+			},
+			records: 10,
+		},
+		"Rate": {
+			c: map[string]interface{}{
+				"records": 21,
+				"rate":    map[string]interface{}{"events": 1000},
+			},
+			records: 21,
+			minTime: 20 * time.Millisecond,
+		},
+		"Workers": {
+			c: map[string]interface{}{
+				"records":    100,
+				"workers":    4,
+				"queue_size": 8,
+			},
+			records: 100,
+		},
+		"Workers Max Records": {
+			c: map[string]interface{}{
+				"records":     7,
+				"interval":    "1ms",
+				"max_records": 20,
+				"workers":     3,
+			},
+			records:   20,
+			intervals: 2,
+		},
+		"Max Records": {
+			c: map[string]interface{}{
+				"records":     7,
+				"interval":    "1ms",
+				"max_records": 20,
+			},
+			records:   20,
+			intervals: 2,
+		},
+		"Max Bytes": {
+			c: map[string]interface{}{
+				"records":   1000,
+				"interval":  "1ms",
+				"max_bytes": 5000,
+			},
+			records:  -1,
+			maxBytes: 5000,
+		},
+		"Duration": {
+			c: map[string]interface{}{
+				"duration": "50ms",
+				"profile":  map[string]interface{}{"type": "ramp", "from": 1000, "to": 1000, "duration": "1s"},
+			},
+			records: -1,
+			minTime: 50 * time.Millisecond,
+		},
+	}
+	for name, tc := range tests {
+		c := ucfg.MustNewFrom(tc.c)
+		assert.Nil(t, c.SetChild("generator", -1, ucfg.MustNewFrom(map[string]interface{}{"type": "aws:vpcflow"})))
+		assert.Nil(t, c.SetChild("output", -1, ucfg.MustNewFrom(map[string]interface{}{"type": testOutputName})))
+
+		r, err := New(c)
+		assert.Nil(t, err, name)
+		start := time.Now()
+		err = r.Execute(context.Background())
+		assert.Nil(t, err, name)
+		assert.GreaterOrEqual(t, time.Since(start), tc.minTime, name)
+
+		out := lastTestOutput
+		if tc.records >= 0 {
+			assert.Len(t, out.records, tc.records, name)
+		} else {
+			assert.NotEmpty(t, out.records, name)
+		}
+		assert.Equal(t, tc.intervals, out.intervals, name)
+		assert.True(t, out.closed, name)
+		assert.Equal(t, uint64(len(out.records)), r.Stats().Records.Load(), name)
+		var size uint64
+		for _, b := range out.records {
+			size += uint64(len(b))
+		}
+		assert.Equal(t, size, r.Stats().Bytes.Load(), name)
+		if tc.maxBytes > 0 {
+			assert.LessOrEqual(t, size, tc.maxBytes, name)
+			assert.Greater(t, size, tc.maxBytes-200, name)
+		}
+	}
+}
+
+func TestExecuteCancel(t *testing.T) {
+	c := ucfg.MustNewFrom(map[string]interface{}{
+		"records":  1,
+		"interval": "1h",
+	})
+	assert.Nil(t, c.SetChild("generator", -1, ucfg.MustNewFrom(map[string]interface{}{"type": "aws:vpcflow"})))
+	assert.Nil(t, c.SetChild("output", -1, ucfg.MustNewFrom(map[string]interface{}{"type": testOutputName})))
+
+	r, err := New(c)
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- r.Execute(ctx)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err = <-done:
+	case <-time.After(time.Second):
+		t.Fatal("runner did not stop after cancel")
+	}
+	assert.Nil(t, err)
+	assert.True(t, lastTestOutput.closed)
+	assert.Len(t, lastTestOutput.records, 1)
+}
+
+type slowOutput struct {
+	testOutput
+}
+
+func (o *slowOutput) Close() error {
+	time.Sleep(time.Second)
+	return nil
+}
+
+func TestDrainTimeout(t *testing.T) {
+	r := Runner{
+		config: config{DrainTimeout: 10 * time.Millisecond},
+		sinks:  []*sink{{typ: "slow", output: &slowOutput{}}},
+	}
+	err := r.closeOutputs()
+	assert.NotNil(t, err)
+	assert.Equal(t, "closing output slow timed out after 10ms", err.Error())
+}
+
+func TestSeed(t *testing.T) {
+	run := func(seed int64) [][]byte {
+		c := ucfg.MustNewFrom(map[string]interface{}{
+			"records": 20,
+			"seed":    seed,
+		})
+		assert.Nil(t, c.SetChild("generator", -1, ucfg.MustNewFrom(map[string]interface{}{"type": "cisco:asa", "include_timestamp": false})))
+		assert.Nil(t, c.SetChild("output", -1, ucfg.MustNewFrom(map[string]interface{}{"type": testOutputName})))
+		r, err := New(c)
+		assert.Nil(t, err)
+		assert.Nil(t, r.Execute(context.Background()))
+		return lastTestOutput.records
+	}
+	first := run(42)
+	assert.Len(t, first, 20)
+	assert.Equal(t, first, run(42))
+	assert.NotEqual(t, first, run(43))
+}
+
+func TestTime(t *testing.T) {
+	gen := map[string]interface{}{"type": "clf"}
+	out := map[string]interface{}{"type": testOutputName}
+	tests := map[string]struct {
+		time        map[string]interface{}
+		records     int
+		first, last string
+		hasError    bool
+		errorString string
+	}{
+		"Step": {
+			time:    map[string]interface{}{"start": "2020-01-01T00:00:00Z", "end": "2020-01-01T00:00:10Z", "step": "1s"},
+			records: 10,
+			first:   "[01/Jan/2020:00:00:01 +0000]",
+			last:    "[01/Jan/2020:00:00:10 +0000]",
+		},
+		"No start": {
+			time:        map[string]interface{}{"step": "1s"},
+			hasError:    true,
+			errorString: "missing required field accessing 'time.start'",
+		},
+		"Bad start": {
+			time:        map[string]interface{}{"start": "yesterday"},
+			hasError:    true,
+			errorString: "'yesterday' is not a valid time expected an RFC 3339 timestamp or a duration accessing 'time.start'",
+		},
+		"Speed and step": {
+			time:        map[string]interface{}{"start": "-1h", "speed": 10, "step": "1s"},
+			hasError:    true,
+			errorString: "only one of 'speed' or 'step' may be set accessing 'time'",
+		},
+		"End before start": {
+			time:        map[string]interface{}{"start": "-1h", "end": "-2h"},
+			hasError:    true,
+			errorString: "'end' must be after 'start' accessing 'time'",
+		},
+	}
+	for name, tc := range tests {
+		c := ucfg.MustNewFrom(map[string]interface{}{
+			"generator": gen,
+			"output":    out,
+			"interval":  "1ms",
+			"records":   3,
+			"time":      tc.time,
+		}, ucfg.PathSep("."))
+		r, err := New(c)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+			continue
+		}
+		assert.Nil(t, err, name)
+		assert.Nil(t, r.Execute(context.Background()), name)
+		records := lastTestOutput.records
+		if assert.Len(t, records, tc.records, name) {
+			assert.Contains(t, string(records[0]), tc.first, name)
+			assert.Contains(t, string(records[len(records)-1]), tc.last, name)
+		}
+	}
+}
+
+func TestDisorder(t *testing.T) {
+	run := func(records int, disorder map[string]interface{}) [][]byte {
+		c := map[string]interface{}{
+			"generator": map[string]interface{}{"type": "clf"},
+			"output":    map[string]interface{}{"type": testOutputName},
+			"records":   records,
+			"seed":      42,
+			"time":      map[string]interface{}{"start": "2020-01-01T00:00:00Z", "step": "1s"},
+		}
+		if disorder != nil {
+			c["disorder"] = disorder
+		}
+		r, err := New(ucfg.MustNewFrom(c, ucfg.PathSep(".")))
+		assert.Nil(t, err)
+		assert.Nil(t, r.Execute(context.Background()))
+		return lastTestOutput.records
+	}
+	timestamp := func(b []byte) time.Time {
+		s := string(b)
+		ts, err := time.Parse("02/Jan/2006:15:04:05 -0700", s[strings.Index(s, "[")+1:strings.Index(s, "]")])
+		assert.Nil(t, err)
+		return ts
+	}
+	inOrder := run(50, nil)
+	assert.Len(t, inOrder, 50)
+
+	late := run(50, map[string]interface{}{"late": map[string]interface{}{"fraction": 1, "delay": "1h"}})
+	if assert.Len(t, late, 50) {
+		for i := range late {
+			assert.True(t, timestamp(late[i]).Before(timestamp(inOrder[i])))
+		}
+	}
+
+	// The last records written are taken from a window that holds the
+	// next 9 records generated.
+	generated := map[string]int{}
+	for _, b := range run(59, nil) {
+		generated[string(b)]++
+	}
+	reordered := run(50, map[string]interface{}{"reorder": map[string]interface{}{"window": 10}})
+	assert.Len(t, reordered, 50)
+	assert.NotEqual(t, inOrder, reordered)
+	for _, b := range reordered {
+		assert.Equal(t, 1, generated[string(b)])
+		generated[string(b)]--
+	}
+
+	duplicated := run(50, map[string]interface{}{"duplicate": map[string]interface{}{"fraction": 1}})
+	if assert.Len(t, duplicated, 50) {
+		for i := 0; i < 50; i += 2 {
+			assert.Equal(t, inOrder[i/2], duplicated[i])
+			assert.Equal(t, inOrder[i/2], duplicated[i+1])
+		}
+	}
+
+	_, err := New(ucfg.MustNewFrom(map[string]interface{}{
+		"generator": map[string]interface{}{"type": "clf"},
+		"output":    map[string]interface{}{"type": testOutputName},
+		"disorder":  map[string]interface{}{"late": map[string]interface{}{"fraction": 0.1, "delay": "1m", "distribution": "normal"}},
+	}, ucfg.PathSep(".")))
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "'normal' is not a valid value for 'distribution' expected one of uniform, exponential accessing 'disorder.late'", err.Error())
+	}
+}
+
+func TestOutputs(t *testing.T) {
+	tests := map[string]struct {
+		outputs     []interface{}
+		hasError    bool
+		errorString string
+		records     int
+		dropped     uint64
+	}{
+		"Fan out": {
+			outputs: []interface{}{
+				map[string]interface{}{"type": testOutputName},
+				map[string]interface{}{"type": testOutputName},
+			},
+			records: 5,
+		},
+		"Drop": {
+			outputs: []interface{}{
+				map[string]interface{}{"type": failOutputName, "on_error": "drop"},
+				map[string]interface{}{"type": testOutputName},
+			},
+			records: 5,
+			dropped: 5,
+		},
+		"Fail": {
+			outputs: []interface{}{
+				map[string]interface{}{"type": testOutputName},
+				map[string]interface{}{"type": failOutputName},
+			},
+			hasError:    true,
+			errorString: "output runner:fail: write failed",
+			records:     1,
+		},
+	}
+	for name, tc := range tests {
+		c := ucfg.MustNewFrom(map[string]interface{}{
+			"records": 5,
+			"outputs": tc.outputs,
+		})
+		assert.Nil(t, c.SetChild("generator", -1, ucfg.MustNewFrom(map[string]interface{}{"type": "aws:vpcflow"})), name)
+		r, err := New(c)
+		assert.Nil(t, err, name)
+		err = r.Execute(context.Background())
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		} else {
+			assert.Nil(t, err, name)
+		}
+		assert.Len(t, lastTestOutput.records, tc.records, name)
+		assert.True(t, lastTestOutput.closed, name)
+		assert.Equal(t, tc.dropped, r.Stats().Dropped.Load(), name)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	gen := map[string]interface{}{"type": "aws:vpcflow"}
+	out := map[string]interface{}{"type": testOutputName}
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Output": {
+			c: map[string]interface{}{"generator": gen, "output": out},
+		},
+		"Outputs": {
+			c: map[string]interface{}{"generator": gen, "outputs": []interface{}{out, out}},
+		},
+		"No output": {
+			c:           map[string]interface{}{"generator": gen},
+			hasError:    true,
+			errorString: "one of 'output' or 'outputs' is required accessing config",
+		},
+		"Both": {
+			c:           map[string]interface{}{"generator": gen, "output": out, "outputs": []interface{}{out}},
+			hasError:    true,
+			errorString: "only one of 'output' or 'outputs' may be set accessing config",
+		},
+		"No workers": {
+			c:           map[string]interface{}{"generator": gen, "output": out, "workers": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'workers' expected >= 1 accessing config",
+		},
+		"Bad policy": {
+			c:           map[string]interface{}{"generator": gen, "output": map[string]interface{}{"type": testOutputName, "on_error": "ignore"}},
+			hasError:    true,
+			errorString: "'ignore' is not a valid value for 'on_error' expected one of fail, skip, drop, retry, reconnect accessing 'output'",
+		},
+	}
+	for name, tc := range tests {
+		_, err := New(ucfg.MustNewFrom(tc.c))
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	gen := map[string]interface{}{"type": "aws:vpcflow"}
+	tests := map[string]struct {
+		c           map[string]interface{}
+		hasError    bool
+		errorString string
+	}{
+		"Valid": {
+			c: map[string]interface{}{"generator": gen, "output": map[string]interface{}{"type": "file", "filename": "/nonexistent/spigot.log"}},
+		},
+		"Unknown generator": {
+			c:           map[string]interface{}{"generator": map[string]interface{}{"type": "bob"}, "output": map[string]interface{}{"type": testOutputName}},
+			hasError:    true,
+			errorString: "generator: Input bob not registered",
+		},
+		"Bad output": {
+			c:           map[string]interface{}{"generator": gen, "outputs": []interface{}{map[string]interface{}{"type": "file"}}},
+			hasError:    true,
+			errorString: "you must specify filename or directory and pattern accessing 'outputs.0'",
+		},
+		"Bad records": {
+			c:           map[string]interface{}{"generator": gen, "output": map[string]interface{}{"type": testOutputName}, "records": 0},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'records' expected >= 1 accessing config",
+		},
+		"Negative records": {
+			c:           map[string]interface{}{"generator": gen, "output": map[string]interface{}{"type": testOutputName}, "records": -1},
+			hasError:    true,
+			errorString: "'-1' is not a valid value for 'records' expected >= 1 accessing config",
+		},
+		"All errors": {
+			c: map[string]interface{}{
+				"generator": map[string]interface{}{"type": "bob"},
+				"output":    map[string]interface{}{"type": "alice"},
+				"workers":   0,
+			},
+			hasError:    true,
+			errorString: "'0' is not a valid value for 'workers' expected >= 1 accessing config\ngenerator: Input bob not registered\noutput: Input alice not registered",
+		},
+	}
+	for name, tc := range tests {
+		err := Validate(ucfg.MustNewFrom(tc.c, ucfg.PathSep(".")))
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		}
+		if !tc.hasError {
+			assert.Nil(t, err, name)
+		}
+	}
+}
+
+func TestOnError(t *testing.T) {
+	tests := map[string]struct {
+		c           map[string]interface{}
+		failures    int
+		perInstance bool
+		hasError    bool
+		errorString string
+		records     int
+		writeErrors uint64
+		retries     uint64
+		reconnects  uint64
+		dropped     uint64
+	}{
+		"Fail": {
+			c:           map[string]interface{}{},
+			failures:    1,
+			hasError:    true,
+			errorString: "output runner:flaky: connection reset",
+			writeErrors: 1,
+		},
+		"Skip": {
+			c:           map[string]interface{}{"on_error": "skip"},
+			failures:    2,
+			records:     3,
+			writeErrors: 2,
+			dropped:     2,
+		},
+		"Retry": {
+			c:           map[string]interface{}{"on_error": "retry", "retry": map[string]interface{}{"initial_backoff": "1ms"}},
+			failures:    3,
+			records:     5,
+			writeErrors: 3,
+			retries:     3,
+		},
+		"Retry Gives Up": {
+			c:           map[string]interface{}{"on_error": "retry", "retry": map[string]interface{}{"initial_backoff": "1ms", "max_attempts": 3}},
+			failures:    10,
+			hasError:    true,
+			errorString: "output runner:flaky: giving up after 3 attempts: connection reset",
+			writeErrors: 3,
+			retries:     2,
+		},
+		"Reconnect": {
+			c:           map[string]interface{}{"on_error": "reconnect", "retry": map[string]interface{}{"initial_backoff": "1ms"}},
+			failures:    10,
+			perInstance: true,
+			records:     5,
+			writeErrors: 1,
+			retries:     1,
+			reconnects:  1,
+		},
+		"Output Overrides Runner": {
+			c: map[string]interface{}{
+				"on_error": "fail",
+				"output":   map[string]interface{}{"type": flakyOutputName, "on_error": "retry", "retry": map[string]interface{}{"initial_backoff": "1ms"}},
+			},
+			failures:    1,
+			records:     5,
+			writeErrors: 1,
+			retries:     1,
+		},
+	}
+	for name, tc := range tests {
+		flakyFailures, flakyPerInstance, flakyOutputs = tc.failures, tc.perInstance, 0
+		c := ucfg.MustNewFrom(tc.c)
+		assert.Nil(t, c.SetInt("records", -1, 5), name)
+		assert.Nil(t, c.SetChild("generator", -1, ucfg.MustNewFrom(map[string]interface{}{"type": "aws:vpcflow"})), name)
+		if !c.HasField("output") {
+			assert.Nil(t, c.SetChild("output", -1, ucfg.MustNewFrom(map[string]interface{}{"type": flakyOutputName})), name)
+		}
+		r, err := New(c)
+		assert.Nil(t, err, name)
+		err = r.Execute(context.Background())
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		} else {
+			assert.Nil(t, err, name)
+		}
+		s := r.Stats()
+		assert.Len(t, lastTestOutput.records, tc.records, name)
+		assert.Equal(t, tc.writeErrors, s.WriteErrors.Load(), name)
+		assert.Equal(t, tc.retries, s.Retries.Load(), name)
+		assert.Equal(t, tc.reconnects, s.Reconnects.Load(), name)
+		assert.Equal(t, tc.dropped, s.Dropped.Load(), name)
+	}
+}
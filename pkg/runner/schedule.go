@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schedule is a cron expression with the five fields minute, hour,
+// day of month, month and day of week.  Each field is "*", a value, a
+// range "a-b" or a list of them separated by commas, and any of these
+// but a single value may be followed by "/step".  Months and days of
+// the week may be given by their first three letters, and Sunday is
+// both 0 and 7.  As in cron, when both the day of month and the day of
+// week are restricted a day matches if either does.  The macros
+// @yearly, @monthly, @weekly, @daily and @hourly are also accepted.
+type schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are set when the field starts with "*",
+	// which changes how day of month and day of week are combined.
+	domStar, dowStar bool
+}
+
+type scheduleField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var scheduleFields = []scheduleField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+var scheduleMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxScheduleSearch bounds the search for the next time a schedule
+// fires, so that schedules that never fire, such as "0 0 30 2 *", do
+// not loop forever.
+const maxScheduleSearch = 5 * 366 * 24 * time.Hour
+
+func (s *schedule) Unpack(v string) error {
+	expr := strings.TrimSpace(v)
+	if m, ok := scheduleMacros[strings.ToLower(expr)]; ok {
+		expr = m
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != len(scheduleFields) {
+		return fmt.Errorf("'%s' is not a valid schedule expected 5 fields: minute hour day-of-month month day-of-week", v)
+	}
+	sets := make([]uint64, len(fields))
+	for i, f := range fields {
+		set, err := scheduleFields[i].parse(f)
+		if err != nil {
+			return fmt.Errorf("'%s' is not a valid schedule: %w", v, err)
+		}
+		sets[i] = set
+	}
+	*s = schedule{
+		minute:  sets[0],
+		hour:    sets[1],
+		dom:     sets[2],
+		month:   sets[3],
+		dow:     sets[4],
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+	// Sunday is both 0 and 7.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	if s.next(time.Now()).IsZero() {
+		return fmt.Errorf("'%s' is not a valid schedule: it never fires", v)
+	}
+	return nil
+}
+
+// parse returns the set of values of a field as a bit set.
+func (f scheduleField) parse(s string) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(s, ",") {
+		expr, step := item, 1
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid step '%s' in %s", item[i+1:], f.name)
+			}
+			expr, step = item[:i], n
+		}
+		low, high := f.min, f.max
+		switch {
+		case expr == "*":
+		case strings.Contains(expr, "-"):
+			i := strings.IndexByte(expr, '-')
+			var err error
+			if low, err = f.value(expr[:i]); err != nil {
+				return 0, err
+			}
+			if high, err = f.value(expr[i+1:]); err != nil {
+				return 0, err
+			}
+			if high < low {
+				return 0, fmt.Errorf("invalid range '%s' in %s", expr, f.name)
+			}
+		default:
+			var err error
+			if low, err = f.value(expr); err != nil {
+				return 0, err
+			}
+			// "a/step" runs from a to the end of the field.
+			if step == 1 {
+				high = low
+			}
+		}
+		for v := low; v <= high; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func (f scheduleField) value(s string) (int, error) {
+	for i, name := range f.names {
+		if name != "" && strings.EqualFold(s, name) {
+			return i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("'%s' is not a valid %s expected %d-%d", s, f.name, f.min, f.max)
+	}
+	return v, nil
+}
+
+// next returns the first time after t that the schedule fires, in the
+// location of t, or the zero time if it does not fire within five
+// years.
+func (s *schedule) next(t time.Time) time.Time {
+	loc := t.Location()
+	limit := t.Add(maxScheduleSearch)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+		case !s.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Truncate(time.Minute).Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (s *schedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
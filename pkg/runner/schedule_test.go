@@ -0,0 +1,101 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule(t *testing.T) {
+	// 2024-01-05 is a Friday.
+	from := time.Date(2024, 1, 5, 16, 57, 30, 0, time.UTC)
+	tests := map[string]struct {
+		expr        string
+		next        []string
+		hasError    bool
+		errorString string
+	}{
+		"Every minute": {
+			expr: "* * * * *",
+			next: []string{"2024-01-05T16:58:00Z", "2024-01-05T16:59:00Z"},
+		},
+		"Business hours": {
+			expr: "*/5 9-17 * * 1-5",
+			next: []string{"2024-01-05T17:00:00Z", "2024-01-05T17:05:00Z", "2024-01-05T17:10:00Z"},
+		},
+		"Next week": {
+			expr: "0 9 * * mon-fri",
+			next: []string{"2024-01-08T09:00:00Z", "2024-01-09T09:00:00Z"},
+		},
+		"Sunday is 7": {
+			expr: "30 2 * * 7",
+			next: []string{"2024-01-07T02:30:00Z", "2024-01-14T02:30:00Z"},
+		},
+		"Day of month or week": {
+			expr: "0 0 1 * fri",
+			next: []string{"2024-01-12T00:00:00Z", "2024-01-19T00:00:00Z", "2024-01-26T00:00:00Z", "2024-02-01T00:00:00Z"},
+		},
+		"List and step": {
+			expr: "15,45 10/6 * * *",
+			next: []string{"2024-01-05T22:15:00Z", "2024-01-05T22:45:00Z", "2024-01-06T10:15:00Z"},
+		},
+		"Leap day": {
+			expr: "0 12 29 feb *",
+			next: []string{"2024-02-29T12:00:00Z", "2028-02-29T12:00:00Z"},
+		},
+		"Macro": {
+			expr: "@monthly",
+			next: []string{"2024-02-01T00:00:00Z", "2024-03-01T00:00:00Z"},
+		},
+		"Too few fields": {
+			expr:        "0 9 * *",
+			hasError:    true,
+			errorString: "'0 9 * *' is not a valid schedule expected 5 fields: minute hour day-of-month month day-of-week",
+		},
+		"Out of range": {
+			expr:        "0 24 * * *",
+			hasError:    true,
+			errorString: "'0 24 * * *' is not a valid schedule: '24' is not a valid hour expected 0-23",
+		},
+		"Bad step": {
+			expr:        "*/0 * * * *",
+			hasError:    true,
+			errorString: "'*/0 * * * *' is not a valid schedule: invalid step '0' in minute",
+		},
+		"Never": {
+			expr:        "0 0 30 feb *",
+			hasError:    true,
+			errorString: "'0 0 30 feb *' is not a valid schedule: it never fires",
+		},
+	}
+	for name, tc := range tests {
+		var s schedule
+		err := s.Unpack(tc.expr)
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+			continue
+		}
+		assert.Nil(t, err, name)
+		next := from
+		for _, want := range tc.next {
+			next = s.next(next)
+			assert.Equal(t, want, next.Format(time.RFC3339), name)
+		}
+	}
+}
+
+func TestScheduleConfig(t *testing.T) {
+	c := ucfg.MustNewFrom(map[string]interface{}{
+		"generator": map[string]interface{}{"type": "clf"},
+		"output":    map[string]interface{}{"type": testOutputName},
+		"schedule":  "*/5 9-17 * * 1-5",
+		"interval":  "5m",
+	})
+	_, err := New(c)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "only one of 'schedule' or 'interval' may be set accessing config", err.Error())
+	}
+}
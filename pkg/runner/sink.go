@@ -0,0 +1,186 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+)
+
+const (
+	OnErrorFail      = "fail"
+	OnErrorSkip      = "skip"
+	OnErrorDrop      = "drop"
+	OnErrorRetry     = "retry"
+	OnErrorReconnect = "reconnect"
+)
+
+var onErrorPolicies = []string{OnErrorFail, OnErrorSkip, OnErrorDrop, OnErrorRetry, OnErrorReconnect}
+
+func validateOnError(policy string) error {
+	for _, p := range onErrorPolicies {
+		if policy == p {
+			return nil
+		}
+	}
+	return fmt.Errorf("'%s' is not a valid value for 'on_error' expected one of %s", policy, strings.Join(onErrorPolicies, ", "))
+}
+
+// retryConfig controls the "retry" and "reconnect" policies.  The
+// wait before each new attempt starts at "initial_backoff" and
+// doubles up to "max_backoff".
+type retryConfig struct {
+	MaxAttempts    int           `config:"max_attempts"`
+	InitialBackoff time.Duration `config:"initial_backoff"`
+	MaxBackoff     time.Duration `config:"max_backoff"`
+}
+
+func defaultRetryConfig() retryConfig {
+	return retryConfig{
+		MaxAttempts:    5,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+	}
+}
+
+func (c *retryConfig) Validate() error {
+	if c.MaxAttempts < 0 {
+		return fmt.Errorf("'%d' is not a valid value for 'max_attempts' expected >= 0", c.MaxAttempts)
+	}
+	if c.InitialBackoff <= 0 || c.MaxBackoff < c.InitialBackoff {
+		return fmt.Errorf("retry requires 0 < 'initial_backoff' <= 'max_backoff'")
+	}
+	return nil
+}
+
+// sinkConfig holds the settings the runner reads from an output's
+// config.  The rest of the config belongs to the output itself.
+// "on_error" and "retry" default to the runner's settings.
+type sinkConfig struct {
+	Type    string      `config:"type" validate:"required"`
+	OnError string      `config:"on_error"`
+	Retry   retryConfig `config:"retry"`
+}
+
+func (c *sinkConfig) Validate() error {
+	return validateOnError(c.OnError)
+}
+
+// sink is one of the outputs of a runner together with its error
+// policy.
+type sink struct {
+	typ     string
+	cfg     *ucfg.Config
+	onError string
+	retry   retryConfig
+	output  output.Output
+	// closed is set when output has been closed and not yet replaced,
+	// after a failed reconnect.
+	closed bool
+	// stats is shared by all sinks of a runner.
+	stats  *Stats
+	logged bool
+}
+
+func newSink(cfg *ucfg.Config, c sinkConfig, stats *Stats) (*sink, error) {
+	if err := cfg.Unpack(&c); err != nil {
+		return nil, err
+	}
+	o, err := output.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &sink{typ: c.Type, cfg: cfg, onError: c.OnError, retry: c.Retry, output: o, stats: stats}, nil
+}
+
+// write writes b to the output, applying the error policy if it
+// fails.
+func (s *sink) write(ctx context.Context, b []byte) error {
+	return s.do(ctx, true, func(o output.Output) error {
+		_, err := o.Write(b)
+		return err
+	})
+}
+
+// newInterval starts a new interval on the output, applying the error
+// policy if it fails.
+func (s *sink) newInterval(ctx context.Context) error {
+	return s.do(ctx, false, func(o output.Output) error {
+		return o.NewInterval()
+	})
+}
+
+// do calls op and, if it fails, applies the error policy:
+//
+//	fail:       the error is returned.
+//	skip, drop: the error is logged the first time and ignored.  If
+//	            record is true it is counted as a dropped record.
+//	retry:      op is attempted again after a backoff, up to
+//	            max_attempts attempts in total, 0 meaning no limit.
+//	reconnect:  like retry, but the output is closed and created again
+//	            from its config before each new attempt.
+//
+// Every failed attempt is counted as a write error.
+func (s *sink) do(ctx context.Context, record bool, op func(output.Output) error) error {
+	err := op(s.output)
+	if err == nil {
+		return nil
+	}
+	s.stats.WriteErrors.Add(1)
+
+	switch s.onError {
+	case OnErrorSkip, OnErrorDrop:
+		if record {
+			s.stats.Dropped.Add(1)
+		}
+		if !s.logged {
+			s.logged = true
+			log.Printf("runner: output %s failed, skipping: %v", s.typ, err)
+		}
+		return nil
+	case OnErrorRetry, OnErrorReconnect:
+		backoff := s.retry.InitialBackoff
+		attempt := 1
+		for ; s.retry.MaxAttempts == 0 || attempt < s.retry.MaxAttempts; attempt++ {
+			if err := sleep(ctx, backoff); err != nil {
+				return err
+			}
+			backoff = min(2*backoff, s.retry.MaxBackoff)
+			if s.onError == OnErrorReconnect {
+				if err = s.reconnect(); err != nil {
+					s.stats.WriteErrors.Add(1)
+					continue
+				}
+			}
+			s.stats.Retries.Add(1)
+			if err = op(s.output); err == nil {
+				return nil
+			}
+			s.stats.WriteErrors.Add(1)
+		}
+		return fmt.Errorf("output %s: giving up after %d attempts: %w", s.typ, attempt, err)
+	}
+	return fmt.Errorf("output %s: %w", s.typ, err)
+}
+
+// reconnect closes the output and creates it again from its config.
+// If that fails the closed output is kept, and is not closed again.
+func (s *sink) reconnect() error {
+	if !s.closed {
+		if err := s.output.Close(); err != nil {
+			log.Printf("runner: closing output %s to reconnect: %v", s.typ, err)
+		}
+		s.closed = true
+	}
+	o, err := output.New(s.cfg)
+	if err != nil {
+		return err
+	}
+	s.output, s.closed = o, false
+	s.stats.Reconnects.Add(1)
+	return nil
+}
@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/elastic/go-ucfg"
+	"github.com/leehinman/spigot/pkg/output"
+	"github.com/stretchr/testify/assert"
+)
+
+const reconnectOutputName = "runner:reconnect"
+
+// reconnectOutputs are the outputs created by the reconnect factory,
+// which fails while reconnectFailures is > 0 after the first output
+// has been created.  Writes to the first output fail.
+var (
+	reconnectOutputs  []*reconnectOutput
+	reconnectFailures int
+)
+
+func init() {
+	output.Register(reconnectOutputName, func(*ucfg.Config) (output.Output, error) {
+		if len(reconnectOutputs) > 0 && reconnectFailures > 0 {
+			reconnectFailures--
+			return nil, errors.New("connection refused")
+		}
+		o := &reconnectOutput{first: len(reconnectOutputs) == 0}
+		reconnectOutputs = append(reconnectOutputs, o)
+		return o, nil
+	})
+}
+
+type reconnectOutput struct {
+	testOutput
+	first  bool
+	closes int
+}
+
+func (o *reconnectOutput) Write(b []byte) (int, error) {
+	if o.first {
+		return 0, errors.New("connection reset")
+	}
+	return o.testOutput.Write(b)
+}
+
+func (o *reconnectOutput) Close() error {
+	o.closes++
+	if o.closes > 1 {
+		return errors.New("already closed")
+	}
+	return nil
+}
+
+func TestReconnectCloses(t *testing.T) {
+	tests := map[string]struct {
+		failures    int
+		maxAttempts int
+		hasError    bool
+		errorString string
+		outputs     int
+		reconnects  uint64
+	}{
+		"Factory Fails Once": {
+			failures:   1,
+			outputs:    2,
+			reconnects: 1,
+		},
+		"Gives Up": {
+			failures:    10,
+			maxAttempts: 3,
+			hasError:    true,
+			errorString: "output runner:reconnect: giving up after 3 attempts: connection refused",
+			outputs:     1,
+		},
+	}
+	for name, tc := range tests {
+		reconnectOutputs, reconnectFailures = nil, tc.failures
+		c := ucfg.MustNewFrom(map[string]interface{}{
+			"records":  3,
+			"on_error": "reconnect",
+			"retry":    map[string]interface{}{"initial_backoff": "1ms", "max_attempts": tc.maxAttempts},
+		})
+		assert.Nil(t, c.SetChild("generator", -1, ucfg.MustNewFrom(map[string]interface{}{"type": "aws:vpcflow"})), name)
+		assert.Nil(t, c.SetChild("output", -1, ucfg.MustNewFrom(map[string]interface{}{"type": reconnectOutputName})), name)
+		r, err := New(c)
+		assert.Nil(t, err, name)
+		err = r.Execute(context.Background())
+		if tc.hasError {
+			assert.NotNil(t, err, name)
+			assert.Equal(t, tc.errorString, err.Error(), name)
+		} else {
+			assert.Nil(t, err, name)
+		}
+		assert.Len(t, reconnectOutputs, tc.outputs, name)
+		for i, o := range reconnectOutputs {
+			assert.Equal(t, 1, o.closes, "%s: output %d", name, i)
+		}
+		assert.Equal(t, tc.reconnects, r.Stats().Reconnects.Load(), name)
+	}
+}
@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+)
+
+// timeConfig is the "time" block of a runner.  It dates records from a
+// virtual clock that starts at "start" and either runs "speed" times
+// faster than real time or advances by "step" for every record.
+type timeConfig struct {
+	Start *timeValue    `config:"start" validate:"required"`
+	End   *timeValue    `config:"end"`
+	Speed float64       `config:"speed"`
+	Step  time.Duration `config:"step"`
+}
+
+func (c *timeConfig) Validate() error {
+	if c.Speed != 0 && c.Step != 0 {
+		return fmt.Errorf("only one of 'speed' or 'step' may be set")
+	}
+	if c.Speed < 0 {
+		return fmt.Errorf("'%v' is not a valid value for 'speed' expected > 0", c.Speed)
+	}
+	if c.Step < 0 {
+		return fmt.Errorf("'%v' is not a valid value for 'step' expected > 0", c.Step)
+	}
+	if c.End != nil && !c.End.After(c.Start.Time) {
+		return fmt.Errorf("'end' must be after 'start'")
+	}
+	return nil
+}
+
+// timeValue is a point in time, written either as an RFC 3339
+// timestamp or as a duration relative to when the config is loaded,
+// for example "-168h" for a week ago.
+type timeValue struct {
+	time.Time
+}
+
+func (t *timeValue) Unpack(s string) error {
+	if ts, err := time.Parse(time.RFC3339, s); err == nil {
+		t.Time = ts
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a valid time expected an RFC 3339 timestamp or a duration", s)
+	}
+	t.Time = time.Now().Add(d)
+	return nil
+}